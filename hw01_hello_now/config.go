@@ -0,0 +1,177 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/beevik/ntp"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// envNTPServers is the name of environment variable with comma separated list of NTP servers.
+	envNTPServers = "NTP_SERVERS"
+
+	defaultTimeout = 5 * time.Second
+	defaultVersion = 4
+)
+
+var (
+	// ErrUnknownConfigFormat happens when config file extension is not .toml, .yaml or .yml.
+	ErrUnknownConfigFormat = errors.New("unknown config file format")
+	// ErrInvalidConfig happens when config values are out of range.
+	ErrInvalidConfig = errors.New("invalid config")
+)
+
+var (
+	configPath   string
+	flagServers  string
+	flagTimeout  time.Duration
+	flagVersion  int
+	flagTTL      int
+	flagFormat   string
+	flagOverride = map[string]func(cfg *config){
+		"servers":     func(cfg *config) { cfg.Servers = splitServers(flagServers) },
+		"timeout":     func(cfg *config) { cfg.Timeout = duration(flagTimeout) },
+		"ntp-version": func(cfg *config) { cfg.Version = flagVersion },
+		"ttl":         func(cfg *config) { cfg.TTL = flagTTL },
+		"format":      func(cfg *config) { cfg.Format = flagFormat },
+	}
+)
+
+func init() {
+	flag.StringVar(&configPath, "config", "", "path to TOML or YAML config file")
+	flag.StringVar(&flagServers, "servers", "", "comma separated list of NTP servers, overrides "+envNTPServers)
+	flag.DurationVar(&flagTimeout, "timeout", defaultTimeout, "timeout of single NTP query")
+	flag.IntVar(&flagVersion, "ntp-version", defaultVersion, "NTP protocol version [2-4]")
+	flag.IntVar(&flagTTL, "ttl", 0, "IP TTL of NTP query packets, 0 means system default")
+	flag.StringVar(&flagFormat, "format", formatText, "output format: text, json, rfc3339 or unix")
+}
+
+// duration wraps time.Duration to parse them from "5s"-like strings in config files.
+type duration time.Duration
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (d *duration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = duration(parsed)
+
+	return nil
+}
+
+// config contains all settings of NTP lookup.
+type config struct {
+	Servers []string `toml:"servers" yaml:"servers"`
+	Timeout duration `toml:"timeout" yaml:"timeout"`
+	Version int      `toml:"version" yaml:"version"`
+	TTL     int      `toml:"ttl" yaml:"ttl"`
+	Format  string   `toml:"format" yaml:"format"`
+}
+
+// defaultConfig returns config with built-in pool.
+func defaultConfig() config {
+	servers := make([]string, len(ntpPool))
+	copy(servers, ntpPool)
+
+	return config{
+		Servers: servers,
+		Timeout: duration(defaultTimeout),
+		Version: defaultVersion,
+		Format:  formatText,
+	}
+}
+
+// loadConfig merges settings by priority: defaults, config file, environment, command-line flags.
+func loadConfig(path string, getenv func(string) string) (config, error) {
+	cfg := defaultConfig()
+
+	if path != "" {
+		if err := readConfigFile(path, &cfg); err != nil {
+			return config{}, fmt.Errorf("unable to read config file %s, %w", path, err)
+		}
+	}
+
+	if servers := getenv(envNTPServers); servers != "" {
+		cfg.Servers = splitServers(servers)
+	}
+
+	flag.Visit(func(f *flag.Flag) {
+		if override, ok := flagOverride[f.Name]; ok {
+			override(&cfg)
+		}
+	})
+
+	if err := cfg.validate(); err != nil {
+		return config{}, err
+	}
+
+	return cfg, nil
+}
+
+// readConfigFile decodes file into cfg, format is chosen by file extension.
+func readConfigFile(path string, cfg *config) error {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".toml":
+		_, err = toml.Decode(string(content), cfg)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, cfg)
+	default:
+		err = fmt.Errorf("%w %q", ErrUnknownConfigFormat, filepath.Ext(path))
+	}
+
+	return err
+}
+
+// splitServers converts comma separated list to slice, spaces around hosts are trimmed.
+func splitServers(list string) []string {
+	servers := strings.Split(list, ",")
+	for i := range servers {
+		servers[i] = strings.TrimSpace(servers[i])
+	}
+
+	return servers
+}
+
+// validate checks values are in allowed ranges.
+func (cfg config) validate() error {
+	if err := checkNTPPool(cfg.Servers); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidConfig, err)
+	}
+
+	if cfg.Timeout <= 0 {
+		return fmt.Errorf("%w: timeout must be positive, given %s", ErrInvalidConfig, time.Duration(cfg.Timeout))
+	}
+
+	if cfg.Version < 2 || cfg.Version > 4 {
+		return fmt.Errorf("%w: NTP version must be in range [2-4], given %d", ErrInvalidConfig, cfg.Version)
+	}
+
+	if cfg.TTL < 0 || cfg.TTL > 255 {
+		return fmt.Errorf("%w: TTL must be in range [0-255], given %d", ErrInvalidConfig, cfg.TTL)
+	}
+
+	return checkFormat(cfg.Format)
+}
+
+// queryOptions converts config to options of single NTP query.
+func (cfg config) queryOptions() ntp.QueryOptions {
+	return ntp.QueryOptions{
+		Timeout: time.Duration(cfg.Timeout),
+		Version: cfg.Version,
+		TTL:     cfg.TTL,
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLoadConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hello_now")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	writeFile := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		return path
+	}
+
+	noEnv := func(string) string { return "" }
+
+	for _, tt := range [...]struct {
+		name     string
+		path     string
+		getenv   func(string) string
+		expected config
+		err      error
+	}{
+		{
+			name:     "defaults",
+			getenv:   noEnv,
+			expected: defaultConfig(),
+		},
+		{
+			name: "toml file",
+			path: writeFile("ntp.toml", `
+servers = ["a.example.com", "b.example.com"]
+timeout = "2s"
+version = 3
+ttl = 64
+format = "json"
+`),
+			getenv: noEnv,
+			expected: config{
+				Servers: []string{"a.example.com", "b.example.com"},
+				Timeout: duration(2 * time.Second),
+				Version: 3,
+				TTL:     64,
+				Format:  formatJSON,
+			},
+		},
+		{
+			name: "yaml file",
+			path: writeFile("ntp.yaml", `
+servers:
+  - a.example.com
+timeout: 500ms
+`),
+			getenv: noEnv,
+			expected: config{
+				Servers: []string{"a.example.com"},
+				Timeout: duration(500 * time.Millisecond),
+				Version: defaultVersion,
+				Format:  formatText,
+			},
+		},
+		{
+			name: "environment overrides file",
+			path: writeFile("env.yml", `servers: [a.example.com]`),
+			getenv: func(name string) string {
+				if name == envNTPServers {
+					return "b.example.com, c.example.com"
+				}
+
+				return ""
+			},
+			expected: config{
+				Servers: []string{"b.example.com", "c.example.com"},
+				Timeout: duration(defaultTimeout),
+				Version: defaultVersion,
+				Format:  formatText,
+			},
+		},
+		{
+			name:   "unknown format",
+			path:   writeFile("ntp.json", `{}`),
+			getenv: noEnv,
+			err:    ErrUnknownConfigFormat,
+		},
+		{
+			name:   "invalid version",
+			path:   writeFile("version.toml", `version = 5`),
+			getenv: noEnv,
+			err:    ErrInvalidConfig,
+		},
+		{
+			name:   "invalid ttl",
+			path:   writeFile("ttl.yaml", `ttl: 256`),
+			getenv: noEnv,
+			err:    ErrInvalidConfig,
+		},
+		{
+			name:   "invalid format",
+			path:   writeFile("format.yaml", `format: xml`),
+			getenv: noEnv,
+			err:    ErrInvalidConfig,
+		},
+		{
+			name:   "invalid timeout",
+			path:   writeFile("timeout.yaml", `timeout: -1s`),
+			getenv: noEnv,
+			err:    ErrInvalidConfig,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := loadConfig(tt.path, tt.getenv)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("unexpected error %v, expected %v", err, tt.err)
+			}
+			if tt.err == nil && !reflect.DeepEqual(cfg, tt.expected) {
+				t.Fatalf("invalid config %+v, expected %+v", cfg, tt.expected)
+			}
+		})
+	}
+
+	t.Run("empty server in environment", func(t *testing.T) {
+		_, err := loadConfig("", func(string) string { return "a.example.com,," })
+		if !errors.Is(err, ErrInvalidConfig) {
+			t.Fatalf("unexpected error %v, expected %v", err, ErrInvalidConfig)
+		}
+	})
+}
@@ -1,10 +1,15 @@
 module github.com/PrideSt/otus-golang/hw01_hello_now
 
-go 1.14
+go 1.18
 
 require (
-	bou.ke/monkey v1.0.2
+	github.com/BurntSushi/toml v1.2.1
 	github.com/beevik/ntp v0.3.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
 	github.com/stretchr/testify v1.6.1 // indirect
 	golang.org/x/net v0.0.0-20200602114024-627f9648deb9 // indirect
+	golang.org/x/sys v0.0.0-20200323222414-85ca7c5b95cd // indirect
 )
@@ -0,0 +1,213 @@
+package fakentp
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"sync"
+	"time"
+)
+
+const (
+	packetSize = 48
+
+	modeClient = 3
+	modeServer = 4
+
+	// precision of server clock, -20 as int8 log2 seconds, about 1 microsecond.
+	precision = 0xEC
+)
+
+// ntpEpoch is the start of NTP era 0.
+var ntpEpoch = time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// ErrInvalidKissCode happens when kiss code is longer then 4 ASCII symbols.
+var ErrInvalidKissCode = errors.New("kiss code must contain at most 4 ASCII symbols")
+
+// Options describes behavior of fake NTP server.
+type Options struct {
+	Offset         time.Duration    // server clock offset relative to Now
+	Delay          time.Duration    // network round-trip delay, split equally between request and response
+	Stratum        uint8            // stratum of server, 0 means 1
+	ReferenceID    uint32           // reference ID, "LOCL" by default
+	Leap           uint8            // leap indicator [0-3]
+	RootDelay      time.Duration    // root delay reported by server
+	RootDispersion time.Duration    // root dispersion reported by server
+	KissCode       string           // when set server responds kiss-of-death packets (stratum 0) with them
+	LossRate       float64          // probability [0-1] to drop request without response
+	Now            func() time.Time // clock of server, time.Now by default
+}
+
+// Server is in-process UDP NTP responder.
+type Server struct {
+	opts Options
+	conn *net.UDPConn
+	wg   sync.WaitGroup
+
+	mu       sync.Mutex
+	rnd      *rand.Rand
+	requests int
+}
+
+// New starts server on random port of loopback interface.
+func New(opts Options) (*Server, error) {
+	if len(opts.KissCode) > 4 {
+		return nil, fmt.Errorf("%w, given %q", ErrInvalidKissCode, opts.KissCode)
+	}
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	if opts.Stratum == 0 {
+		opts.Stratum = 1
+	}
+	if opts.ReferenceID == 0 {
+		opts.ReferenceID = asciiID("LOCL")
+	}
+
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		return nil, fmt.Errorf("unable to listen udp, %w", err)
+	}
+
+	s := &Server{
+		opts: opts,
+		conn: conn,
+		rnd:  rand.New(rand.NewSource(time.Now().UnixNano())), //nolint:gosec
+	}
+
+	s.wg.Add(1)
+	go s.serve()
+
+	return s, nil
+}
+
+// Host returns host of server.
+func (s *Server) Host() string {
+	return s.conn.LocalAddr().(*net.UDPAddr).IP.String()
+}
+
+// Port returns port of server.
+func (s *Server) Port() int {
+	return s.conn.LocalAddr().(*net.UDPAddr).Port
+}
+
+// Addr returns server address in host:port form.
+func (s *Server) Addr() string {
+	return s.conn.LocalAddr().String()
+}
+
+// Requests returns count of received requests, dropped ones included.
+func (s *Server) Requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.requests
+}
+
+// Close stops server and waits for all pending responses.
+func (s *Server) Close() error {
+	err := s.conn.Close()
+	s.wg.Wait()
+
+	return err
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+
+	buffer := make([]byte, 1024)
+	for {
+		n, addr, err := s.conn.ReadFromUDP(buffer)
+		if err != nil {
+			// connection closed
+			return
+		}
+
+		if s.drop() || n < packetSize || buffer[0]&0x07 != modeClient {
+			continue
+		}
+
+		request := make([]byte, packetSize)
+		copy(request, buffer)
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+
+			// symmetric delay doesn't affect offset, but increases RTT
+			time.Sleep(s.opts.Delay / 2)
+			response := s.response(request, s.now())
+			time.Sleep(s.opts.Delay / 2)
+
+			_, _ = s.conn.WriteToUDP(response, addr)
+		}()
+	}
+}
+
+// drop counts request and decides whether it must be lost.
+func (s *Server) drop() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests++
+
+	return s.opts.LossRate > 0 && s.rnd.Float64() < s.opts.LossRate
+}
+
+// now returns time of server clock.
+func (s *Server) now() time.Time {
+	return s.opts.Now().Add(s.opts.Offset)
+}
+
+// response builds server packet for given client request.
+// @see https://tools.ietf.org/html/rfc5905#section-7.3
+func (s *Server) response(request []byte, receiveTime time.Time) []byte {
+	version := (request[0] >> 3) & 0x07
+	stratum, referenceID := s.opts.Stratum, s.opts.ReferenceID
+	if s.opts.KissCode != "" {
+		stratum, referenceID = 0, asciiID(s.opts.KissCode)
+	}
+
+	p := make([]byte, packetSize)
+	p[0] = s.opts.Leap<<6 | version<<3 | modeServer
+	p[1] = stratum
+	p[2] = 4 // poll interval, log2 seconds
+	p[3] = precision
+	binary.BigEndian.PutUint32(p[4:], toShortTime(s.opts.RootDelay))
+	binary.BigEndian.PutUint32(p[8:], toShortTime(s.opts.RootDispersion))
+	binary.BigEndian.PutUint32(p[12:], referenceID)
+	binary.BigEndian.PutUint64(p[16:], toTime(receiveTime.Add(-time.Minute)))
+	// origin time is the transmit time of request
+	copy(p[24:32], request[40:48])
+	binary.BigEndian.PutUint64(p[32:], toTime(receiveTime))
+	binary.BigEndian.PutUint64(p[40:], toTime(s.now()))
+
+	return p
+}
+
+// toTime converts time to 64-bit NTP timestamp, 32 bits of seconds and 32 bits of fraction.
+func toTime(t time.Time) uint64 {
+	d := uint64(t.Sub(ntpEpoch))
+	sec := d / uint64(time.Second)
+	frac := ((d % uint64(time.Second)) << 32) / uint64(time.Second)
+
+	return sec<<32 | frac
+}
+
+// toShortTime converts duration to 32-bit NTP short format, 16 bits of seconds and 16 bits of fraction.
+func toShortTime(d time.Duration) uint32 {
+	sec := uint64(d) / uint64(time.Second)
+	frac := ((uint64(d) % uint64(time.Second)) << 16) / uint64(time.Second)
+
+	return uint32(sec<<16 | frac)
+}
+
+// asciiID packs up to 4 ASCII symbols into reference ID.
+func asciiID(code string) uint32 {
+	var b [4]byte
+	copy(b[:], code)
+
+	return binary.BigEndian.Uint32(b[:])
+}
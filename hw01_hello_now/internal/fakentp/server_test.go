@@ -0,0 +1,101 @@
+package fakentp
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/beevik/ntp"
+)
+
+func query(t *testing.T, s *Server, timeout time.Duration) (*ntp.Response, error) {
+	t.Helper()
+
+	return ntp.QueryWithOptions(s.Host(), ntp.QueryOptions{Port: s.Port(), Timeout: timeout})
+}
+
+func TestServer(t *testing.T) {
+	t.Run("offset and stratum", func(t *testing.T) {
+		s, err := New(Options{Offset: time.Hour, Stratum: 2, ReferenceID: 0xC0A80001})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer s.Close()
+
+		resp, err := query(t, s, time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := resp.Validate(); err != nil {
+			t.Fatal(err)
+		}
+
+		if diff := resp.ClockOffset - time.Hour; diff > 10*time.Millisecond || diff < -10*time.Millisecond {
+			t.Fatalf("invalid offset %s", resp.ClockOffset)
+		}
+		if resp.Stratum != 2 || resp.ReferenceID != 0xC0A80001 {
+			t.Fatalf("invalid stratum %d or reference ID %x", resp.Stratum, resp.ReferenceID)
+		}
+	})
+
+	t.Run("delay", func(t *testing.T) {
+		s, err := New(Options{Delay: 50 * time.Millisecond, RootDispersion: 250 * time.Millisecond})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer s.Close()
+
+		resp, err := query(t, s, time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if resp.RTT < 50*time.Millisecond {
+			t.Fatalf("RTT %s less then delay", resp.RTT)
+		}
+		if diff := resp.ClockOffset; diff > 10*time.Millisecond || diff < -10*time.Millisecond {
+			t.Fatalf("delay changes offset %s", resp.ClockOffset)
+		}
+		if resp.RootDispersion != 250*time.Millisecond {
+			t.Fatalf("invalid root dispersion %s", resp.RootDispersion)
+		}
+	})
+
+	t.Run("kiss of death", func(t *testing.T) {
+		s, err := New(Options{KissCode: "RATE"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer s.Close()
+
+		resp, err := query(t, s, time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if resp.Stratum != 0 || resp.KissCode != "RATE" || resp.Validate() == nil {
+			t.Fatalf("kiss of death expected, stratum %d, code %q", resp.Stratum, resp.KissCode)
+		}
+	})
+
+	t.Run("packet loss", func(t *testing.T) {
+		s, err := New(Options{LossRate: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer s.Close()
+
+		if _, err := query(t, s, 100*time.Millisecond); err == nil {
+			t.Fatal("timeout expected")
+		}
+		if s.Requests() != 1 {
+			t.Fatalf("invalid requests count %d", s.Requests())
+		}
+	})
+
+	t.Run("invalid kiss code", func(t *testing.T) {
+		if _, err := New(Options{KissCode: "TOOLONG"}); !errors.Is(err, ErrInvalidKissCode) {
+			t.Fatalf("unexpected error %v", err)
+		}
+	})
+}
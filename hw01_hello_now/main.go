@@ -1,16 +1,21 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"os"
 	"time"
-
-	"github.com/beevik/ntp"
 )
 
 var (
+	// ntpPool used when servers not configured by flags, environment or config file.
 	ntpPool = []string{
 		"0.ru.pool.ntp.org",
+		"1.ru.pool.ntp.org",
+		"2.ru.pool.ntp.org",
+		"3.ru.pool.ntp.org",
 	}
 )
 
@@ -19,39 +24,74 @@ const (
 	ErrorCodeNTPPoolInvalidConfig = 1 << iota
 	// ErrorCodeNTPLookup happens when NTP service unavailable.
 	ErrorCodeNTPLookup
+	// ErrorCodeMetricsServer happens when metrics endpoint can't be served in watch mode.
+	ErrorCodeMetricsServer
 )
 
 func main() {
-	// choose some NTP host
-	ntpHost, err := getNTPHost(0)
+	flag.Parse()
+
+	os.Exit(run(configPath, os.Getenv, os.Stdout, os.Stderr, time.Now))
+}
+
+// run makes time lookup with settings from config file, environment and flags,
+// writes report to stdout and errors to stderr, returns exit code.
+func run(configPath string, getenv func(string) string, stdout, stderr io.Writer, now func() time.Time) int {
+	cfg, err := loadConfig(configPath, getenv)
 	if err != nil {
 		err := fmt.Errorf("invalid NTP pool configuration, %w", err)
-		_, _ = fmt.Fprintln(os.Stderr, err)
-		os.Exit(ErrorCodeNTPPoolInvalidConfig)
+		_, _ = fmt.Fprintln(stderr, err)
+		return ErrorCodeNTPPoolInvalidConfig
 	}
 
-	// make request to NTP server
-	ntpTime, err := ntp.Time(ntpHost)
+	if watchMode {
+		if err := runWatch(cfg, now); err != nil {
+			_, _ = fmt.Fprintln(stderr, err)
+			if errors.Is(err, ErrInvalidConfig) {
+				return ErrorCodeNTPPoolInvalidConfig
+			}
+			return ErrorCodeMetricsServer
+		}
+
+		return 0
+	}
+
+	// make requests to all NTP servers, skip unreachable ones
+	results := queryPool(cfg.Servers, cfg.queryOptions())
+	for _, res := range results {
+		if res.err != nil {
+			_, _ = fmt.Fprintf(stderr, "skip NTP host %s, %s\n", res.host, res.err)
+		}
+	}
+
+	offset, err := combineOffset(results)
 	if err != nil {
 		err := fmt.Errorf("unable get NTP time, %w", err)
-		_, _ = fmt.Fprintln(os.Stderr, err)
-		os.Exit(ErrorCodeNTPLookup)
+		_, _ = fmt.Fprintln(stderr, err)
+		return ErrorCodeNTPLookup
 	}
 
-	localTime := time.Now()
+	localTime := now()
 
 	// out response to stdout
-	fmt.Printf("current time: %s\nexact time: %s\n", localTime.Round(0).UTC(), ntpTime.Round(0).UTC())
+	if err := writeReport(stdout, cfg.Format, newReport(localTime, offset, results)); err != nil {
+		_, _ = fmt.Fprintln(stderr, err)
+	}
+
+	return 0
 }
 
-func getNTPHost(offset int) (string, error) {
-	if len(ntpPool) == 0 {
-		return "", fmt.Errorf("NTP pool is empty")
+// checkNTPPool returns error when pool can't be used for time lookup.
+func checkNTPPool(pool []string) error {
+	if len(pool) == 0 {
+		return fmt.Errorf("NTP pool is empty")
 	}
 
-	if len(ntpPool) < offset {
-		return "", fmt.Errorf("NTP host offest is out of range [offset/pool size] = [%d/%d]", offset, len(ntpPool))
+	for i, host := range pool {
+		if host == "" {
+			return fmt.Errorf("NTP host at offset %d is empty", i)
+		}
 	}
 
-	return ntpPool[offset], nil
+	return nil
 }
@@ -1,70 +1,167 @@
 package main
 
 import (
+	"bytes"
 	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
-	"bou.ke/monkey"
-	"github.com/beevik/ntp"
+	"github.com/PrideSt/otus-golang/hw01_hello_now/internal/fakentp"
 )
 
-// go test -gcflags=-l
-func TestHelloNow(t *testing.T) {
-	t.Run("test normal behavior", func(t *testing.T) {
-		layout := "2 Jan 2006 15:04:05"
-
-		monkey.Patch(time.Now, func() time.Time {
-			nowTime, err := time.Parse(layout, "9 May 1945 10:03:00")
-			if err != nil {
-				t.Fatal(err)
-			}
-			return nowTime
-		})
+func startServers(t *testing.T, opts ...fakentp.Options) []string {
+	t.Helper()
 
-		monkey.Patch(ntp.Time, func(_ string) (time.Time, error) {
-			ntpTime, err := time.Parse(layout, "9 May 1945 10:03:02")
-			if err != nil {
-				t.Fatal(err)
-			}
-			return ntpTime, nil
-		})
-
-		result, err := catchStdout(main)
+	addrs := make([]string, 0, len(opts))
+	for _, o := range opts {
+		s, err := fakentp.New(o)
 		if err != nil {
 			t.Fatal(err)
 		}
+		t.Cleanup(func() { _ = s.Close() })
 
-		expected := `current time: 1945-05-09 10:03:00 +0000 UTC
-exact time: 1945-05-09 10:03:02 +0000 UTC
-`
-		if string(result) != expected {
-			t.Fatalf("invalid output:\n%s, expected:\n%s", result, expected)
-		}
-	})
+		addrs = append(addrs, s.Addr())
+	}
+
+	return addrs
 }
 
-func catchStdout(runnable func()) (result []byte, err error) {
-	realOut := os.Stdout
-	defer func() { os.Stdout = realOut }()
+// writeConfig creates YAML config file with given servers and short timeout.
+func writeConfig(t *testing.T, servers []string) string {
+	t.Helper()
 
-	r, fakeOut, err := os.Pipe()
+	dir, err := ioutil.TempDir("", "hello_now")
 	if err != nil {
-		return
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	content := "timeout: 200ms\nservers:\n"
+	for _, s := range servers {
+		content += "  - " + s + "\n"
 	}
 
-	os.Stdout = fakeOut
-	runnable()
-	if err = fakeOut.Close(); err != nil {
-		return
+	path := filepath.Join(dir, "ntp.yaml")
+	if err := ioutil.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
 	}
 
-	result, err = ioutil.ReadAll(r)
+	return path
+}
+
+func TestHelloNow(t *testing.T) {
+	layout := "2 Jan 2006 15:04:05"
+	nowTime, err := time.Parse(layout, "9 May 1945 10:03:00")
 	if err != nil {
-		return
+		t.Fatal(err)
 	}
+	now := func() time.Time { return nowTime }
+	noEnv := func(string) string { return "" }
+
+	// checkOutput compares output ignoring sub-second network jitter of exact time
+	checkOutput := func(t *testing.T, output string, exactTime time.Time) {
+		t.Helper()
+
+		lines := strings.Split(output, "\n")
+		if len(lines) != 3 || lines[0] != "current time: 1945-05-09 10:03:00 +0000 UTC" || !strings.HasPrefix(lines[1], "exact time: ") {
+			t.Fatalf("invalid output:\n%s", output)
+		}
+
+		ntpTime, err := time.Parse("2006-01-02 15:04:05.999999999 -0700 MST", strings.TrimPrefix(lines[1], "exact time: "))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := ntpTime.Sub(exactTime); diff > 50*time.Millisecond || diff < -50*time.Millisecond {
+			t.Fatalf("invalid exact time %s, expected %s", ntpTime, exactTime)
+		}
+	}
+
+	t.Run("test normal behavior", func(t *testing.T) {
+		servers := startServers(t, fakentp.Options{Offset: 2 * time.Second})
+
+		var stdout, stderr bytes.Buffer
+		code := run(writeConfig(t, servers), noEnv, &stdout, &stderr, now)
+		if code != 0 {
+			t.Fatalf("unexpected exit code %d, stderr:\n%s", code, stderr.String())
+		}
+
+		checkOutput(t, stdout.String(), nowTime.Add(2*time.Second))
+	})
+
+	t.Run("servers from environment", func(t *testing.T) {
+		servers := startServers(t, fakentp.Options{Offset: -time.Minute})
+		getenv := func(name string) string {
+			if name == envNTPServers {
+				return strings.Join(servers, ",")
+			}
+
+			return ""
+		}
+
+		var stdout, stderr bytes.Buffer
+		if code := run("", getenv, &stdout, &stderr, now); code != 0 {
+			t.Fatalf("unexpected exit code %d, stderr:\n%s", code, stderr.String())
+		}
+
+		checkOutput(t, stdout.String(), nowTime.Add(-time.Minute))
+	})
+
+	t.Run("lost packets and outliers skipped", func(t *testing.T) {
+		servers := startServers(t,
+			fakentp.Options{Offset: 2 * time.Second},
+			fakentp.Options{Offset: 2 * time.Second, Delay: 20 * time.Millisecond},
+			fakentp.Options{Offset: time.Hour},
+			fakentp.Options{LossRate: 1},
+			fakentp.Options{KissCode: "RATE"},
+		)
+
+		var stdout, stderr bytes.Buffer
+		if code := run(writeConfig(t, servers), noEnv, &stdout, &stderr, now); code != 0 {
+			t.Fatalf("unexpected exit code %d, stderr:\n%s", code, stderr.String())
+		}
+
+		checkOutput(t, stdout.String(), nowTime.Add(2*time.Second))
+		if !strings.Contains(stderr.String(), "skip NTP host "+servers[3]) || !strings.Contains(stderr.String(), "kiss of death") {
+			t.Fatalf("skipped hosts not reported:\n%s", stderr.String())
+		}
+	})
 
-	err = r.Close()
-	return
+	t.Run("all servers unavailable", func(t *testing.T) {
+		servers := startServers(t, fakentp.Options{LossRate: 1}, fakentp.Options{KissCode: "DENY"})
+
+		var stdout, stderr bytes.Buffer
+		if code := run(writeConfig(t, servers), noEnv, &stdout, &stderr, now); code != ErrorCodeNTPLookup {
+			t.Fatalf("unexpected exit code %d, expected %d", code, ErrorCodeNTPLookup)
+		}
+		if stdout.Len() != 0 || !strings.Contains(stderr.String(), "unable get NTP time") {
+			t.Fatalf("invalid output, stdout:\n%s\nstderr:\n%s", stdout.String(), stderr.String())
+		}
+	})
+
+	t.Run("servers disagree", func(t *testing.T) {
+		servers := startServers(t, fakentp.Options{Offset: time.Minute}, fakentp.Options{Offset: time.Hour})
+
+		var stdout, stderr bytes.Buffer
+		if code := run(writeConfig(t, servers), noEnv, &stdout, &stderr, now); code != ErrorCodeNTPLookup {
+			t.Fatalf("unexpected exit code %d, expected %d", code, ErrorCodeNTPLookup)
+		}
+		if !strings.Contains(stderr.String(), ErrNoMajority.Error()) {
+			t.Fatalf("invalid stderr:\n%s", stderr.String())
+		}
+	})
+
+	t.Run("invalid configuration", func(t *testing.T) {
+		getenv := func(string) string { return "a.example.com,," }
+
+		var stdout, stderr bytes.Buffer
+		if code := run("", getenv, &stdout, &stderr, now); code != ErrorCodeNTPPoolInvalidConfig {
+			t.Fatalf("unexpected exit code %d, expected %d", code, ErrorCodeNTPPoolInvalidConfig)
+		}
+		if !strings.Contains(stderr.String(), "invalid NTP pool configuration") {
+			t.Fatalf("invalid stderr:\n%s", stderr.String())
+		}
+	})
 }
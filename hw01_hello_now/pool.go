@@ -0,0 +1,141 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/beevik/ntp"
+)
+
+var (
+	// ErrNoResponses happens when no one pool member answered valid response.
+	ErrNoResponses = errors.New("no valid responses from NTP pool")
+	// ErrNoMajority happens when intersection of correctness intervals doesn't cover majority of servers.
+	ErrNoMajority = errors.New("NTP servers disagree, no majority found")
+)
+
+// hostResponse is a result of querying single pool member.
+type hostResponse struct {
+	host     string
+	response *ntp.Response
+	err      error
+}
+
+// interval is a correctness interval of single server, true offset expected somewhere inside them.
+type interval struct {
+	lo, hi time.Duration
+}
+
+// queryPool makes requests to all pool members concurrently, result has the same order as pool.
+func queryPool(pool []string, opts ntp.QueryOptions) []hostResponse {
+	results := make([]hostResponse, len(pool))
+
+	wg := &sync.WaitGroup{}
+	wg.Add(len(pool))
+	for i, host := range pool {
+		go func(i int, host string) {
+			defer wg.Done()
+
+			results[i] = queryHost(host, opts)
+		}(i, host)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// queryHost makes request to single NTP server and validates response, host may contain port.
+func queryHost(host string, opts ntp.QueryOptions) hostResponse {
+	addr := host
+	if h, p, err := net.SplitHostPort(host); err == nil {
+		port, err := strconv.Atoi(p)
+		if err != nil {
+			return hostResponse{host: host, err: fmt.Errorf("invalid port %q, %w", p, err)}
+		}
+		addr, opts.Port = h, port
+	}
+
+	resp, err := ntp.QueryWithOptions(addr, opts)
+	if err != nil {
+		return hostResponse{host: host, err: err}
+	}
+
+	if err := resp.Validate(); err != nil {
+		return hostResponse{host: host, response: resp, err: fmt.Errorf("invalid response, %w", err)}
+	}
+
+	return hostResponse{host: host, response: resp}
+}
+
+// toInterval returns correctness interval [offset - root distance, offset + root distance].
+func toInterval(resp *ntp.Response) interval {
+	distance := resp.RootDistance
+	if distance <= 0 {
+		distance = resp.RTT / 2
+	}
+
+	return interval{resp.ClockOffset - distance, resp.ClockOffset + distance}
+}
+
+// marzullo finds the smallest interval consistent with the largest number of sources,
+// returns them with count of sources which agree with it.
+// @see https://en.wikipedia.org/wiki/Marzullo%27s_algorithm
+func marzullo(intervals []interval) (best interval, count int) {
+	type edge struct {
+		offset time.Duration
+		kind   int // -1 interval starts, +1 interval ends
+	}
+
+	edges := make([]edge, 0, 2*len(intervals))
+	for _, in := range intervals {
+		edges = append(edges, edge{in.lo, -1}, edge{in.hi, +1})
+	}
+
+	// starts go before ends on the same offset, so touching intervals are intersected
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].offset == edges[j].offset {
+			return edges[i].kind < edges[j].kind
+		}
+
+		return edges[i].offset < edges[j].offset
+	})
+
+	current := 0
+	for i, e := range edges {
+		current -= e.kind
+		if current > count {
+			count = current
+			best = interval{e.offset, edges[i+1].offset}
+		}
+	}
+
+	return best, count
+}
+
+// combineOffset rejects unreachable hosts and falsetickers, returns offset agreed by the majority of servers.
+func combineOffset(results []hostResponse) (time.Duration, error) {
+	intervals := make([]interval, 0, len(results))
+	for _, res := range results {
+		if res.err != nil {
+			continue
+		}
+
+		intervals = append(intervals, toInterval(res.response))
+	}
+
+	if len(intervals) == 0 {
+		return 0, ErrNoResponses
+	}
+
+	best, count := marzullo(intervals)
+	if 2*count <= len(intervals) {
+		return 0, fmt.Errorf("%w: %d of %d servers agree", ErrNoMajority, count, len(intervals))
+	}
+
+	return best.lo + (best.hi-best.lo)/2, nil
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/beevik/ntp"
+)
+
+func TestMarzullo(t *testing.T) {
+	for _, tt := range [...]struct {
+		name      string
+		intervals []interval
+		expected  interval
+		count     int
+	}{
+		{
+			name:      "single interval",
+			intervals: []interval{{-10, 10}},
+			expected:  interval{-10, 10},
+			count:     1,
+		},
+		{
+			name:      "all intersected",
+			intervals: []interval{{8, 12}, {11, 13}, {10, 12}},
+			expected:  interval{11, 12},
+			count:     3,
+		},
+		{
+			name:      "one falseticker",
+			intervals: []interval{{8, 12}, {11, 13}, {14, 15}},
+			expected:  interval{11, 12},
+			count:     2,
+		},
+		{
+			name:      "touching intervals",
+			intervals: []interval{{0, 5}, {5, 10}},
+			expected:  interval{5, 5},
+			count:     2,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			best, count := marzullo(tt.intervals)
+			if best != tt.expected || count != tt.count {
+				t.Fatalf("invalid result %v/%d, expected %v/%d", best, count, tt.expected, tt.count)
+			}
+		})
+	}
+}
+
+func TestCombineOffset(t *testing.T) {
+	response := func(offset time.Duration) *ntp.Response {
+		return &ntp.Response{ClockOffset: offset, RootDistance: 10 * time.Millisecond}
+	}
+
+	for _, tt := range [...]struct {
+		name     string
+		results  []hostResponse
+		expected time.Duration
+		err      error
+	}{
+		{
+			name: "unreachable host skipped",
+			results: []hostResponse{
+				{host: "a", err: errors.New("timeout")},
+				{host: "b", response: response(time.Second)},
+			},
+			expected: time.Second,
+		},
+		{
+			name: "outlier rejected",
+			results: []hostResponse{
+				{host: "a", response: response(time.Second)},
+				{host: "b", response: response(time.Second + 4*time.Millisecond)},
+				{host: "c", response: response(time.Hour)},
+			},
+			expected: time.Second + 2*time.Millisecond,
+		},
+		{
+			name: "all hosts unreachable",
+			results: []hostResponse{
+				{host: "a", err: errors.New("timeout")},
+			},
+			err: ErrNoResponses,
+		},
+		{
+			name: "no majority",
+			results: []hostResponse{
+				{host: "a", response: response(time.Second)},
+				{host: "b", response: response(time.Hour)},
+			},
+			err: ErrNoMajority,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			offset, err := combineOffset(tt.results)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("unexpected error %v, expected %v", err, tt.err)
+			}
+			if offset != tt.expected {
+				t.Fatalf("invalid offset %s, expected %s", offset, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/beevik/ntp"
+)
+
+// Output formats of time report.
+const (
+	formatText    = "text"
+	formatJSON    = "json"
+	formatRFC3339 = "rfc3339"
+	formatUnix    = "unix"
+)
+
+// leapNames contains human readable names of ntp.LeapIndicator values.
+var leapNames = map[ntp.LeapIndicator]string{
+	ntp.LeapNoWarning: "none",
+	ntp.LeapAddSecond: "add_second",
+	ntp.LeapDelSecond: "del_second",
+	ntp.LeapNotInSync: "not_in_sync",
+}
+
+// report is a result of time lookup, durations are in nanoseconds.
+type report struct {
+	CurrentTime time.Time      `json:"current_time"`
+	ExactTime   time.Time      `json:"exact_time"`
+	Offset      time.Duration  `json:"offset_ns"`
+	Servers     []serverReport `json:"servers"`
+}
+
+// serverReport is a result of querying single NTP server, response fields are empty on error.
+type serverReport struct {
+	Host           string        `json:"host"`
+	Error          string        `json:"error,omitempty"`
+	Time           *time.Time    `json:"time,omitempty"`
+	Offset         time.Duration `json:"offset_ns"`
+	RTT            time.Duration `json:"rtt_ns"`
+	Precision      time.Duration `json:"precision_ns"`
+	Stratum        uint8         `json:"stratum"`
+	ReferenceID    string        `json:"reference_id,omitempty"`
+	ReferenceTime  *time.Time    `json:"reference_time,omitempty"`
+	RootDelay      time.Duration `json:"root_delay_ns"`
+	RootDispersion time.Duration `json:"root_dispersion_ns"`
+	RootDistance   time.Duration `json:"root_distance_ns"`
+	Leap           string        `json:"leap,omitempty"`
+	MinError       time.Duration `json:"min_error_ns"`
+	KissCode       string        `json:"kiss_code,omitempty"`
+}
+
+// newReport creates report from local time, agreed offset and per-server results.
+func newReport(localTime time.Time, offset time.Duration, results []hostResponse) report {
+	r := report{
+		CurrentTime: localTime.Round(0).UTC(),
+		ExactTime:   localTime.Add(offset).Round(0).UTC(),
+		Offset:      offset,
+		Servers:     make([]serverReport, 0, len(results)),
+	}
+
+	for _, res := range results {
+		r.Servers = append(r.Servers, newServerReport(res))
+	}
+
+	return r
+}
+
+func newServerReport(res hostResponse) serverReport {
+	sr := serverReport{Host: res.host}
+	if res.err != nil {
+		sr.Error = res.err.Error()
+	}
+
+	resp := res.response
+	if resp == nil {
+		return sr
+	}
+
+	serverTime := resp.Time.UTC()
+	referenceTime := resp.ReferenceTime.UTC()
+
+	sr.Time = &serverTime
+	sr.Offset = resp.ClockOffset
+	sr.RTT = resp.RTT
+	sr.Precision = resp.Precision
+	sr.Stratum = resp.Stratum
+	sr.ReferenceID = referenceID(resp.Stratum, resp.ReferenceID)
+	sr.ReferenceTime = &referenceTime
+	sr.RootDelay = resp.RootDelay
+	sr.RootDispersion = resp.RootDispersion
+	sr.RootDistance = resp.RootDistance
+	sr.Leap = leapNames[resp.Leap]
+	sr.MinError = resp.MinError
+	sr.KissCode = resp.KissCode
+
+	return sr
+}
+
+// referenceID formats reference ID, it is ASCII code of clock source for stratum 0 and 1,
+// IPv4 address of upstream server otherwise.
+// @see https://tools.ietf.org/html/rfc5905#section-7.3
+func referenceID(stratum uint8, id uint32) string {
+	b := []byte{byte(id >> 24), byte(id >> 16), byte(id >> 8), byte(id)}
+	if stratum > 1 {
+		return net.IP(b).String()
+	}
+
+	return strings.TrimRight(string(b), "\x00")
+}
+
+// writeReport outputs report in given format.
+func writeReport(w io.Writer, format string, r report) error {
+	var err error
+
+	switch format {
+	case formatText:
+		_, err = fmt.Fprintf(w, "current time: %s\nexact time: %s\n", r.CurrentTime, r.ExactTime)
+	case formatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(r)
+	case formatRFC3339:
+		_, err = fmt.Fprintln(w, r.ExactTime.Format(time.RFC3339Nano))
+	case formatUnix:
+		_, err = fmt.Fprintln(w, formatUnixTime(r.ExactTime))
+	default:
+		err = checkFormat(format)
+	}
+
+	return err
+}
+
+// formatUnixTime returns unix time in seconds with nanoseconds fraction.
+func formatUnixTime(t time.Time) string {
+	sec, nsec := t.Unix(), int64(t.Nanosecond())
+	if sec >= 0 {
+		return fmt.Sprintf("%d.%09d", sec, nsec)
+	}
+
+	// t.Unix() rounds down, so fraction of negative time must be counted from the next second
+	if nsec > 0 {
+		sec++
+		nsec = int64(time.Second) - nsec
+	}
+
+	return fmt.Sprintf("-%d.%09d", -sec, nsec)
+}
+
+// checkFormat returns error when report can't be written in given format.
+func checkFormat(format string) error {
+	switch format {
+	case formatText, formatJSON, formatRFC3339, formatUnix:
+		return nil
+	default:
+		return fmt.Errorf("%w: unknown output format %q", ErrInvalidConfig, format)
+	}
+}
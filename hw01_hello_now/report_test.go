@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/beevik/ntp"
+)
+
+func TestWriteReport(t *testing.T) {
+	localTime := time.Date(1945, 5, 9, 10, 3, 0, 0, time.UTC)
+	serverTime := localTime.Add(2 * time.Second)
+
+	r := newReport(localTime, 2*time.Second+500*time.Millisecond, []hostResponse{
+		{host: "a", response: &ntp.Response{
+			Time:          serverTime,
+			ReferenceTime: serverTime,
+			ClockOffset:   2 * time.Second,
+			RTT:           10 * time.Millisecond,
+			Stratum:       1,
+			ReferenceID:   0x47505300, // GPS
+			Leap:          ntp.LeapAddSecond,
+		}},
+		{host: "b", err: errors.New("timeout")},
+	})
+
+	for _, tt := range [...]struct {
+		format   string
+		expected string
+	}{
+		{
+			format:   formatText,
+			expected: "current time: 1945-05-09 10:03:00 +0000 UTC\nexact time: 1945-05-09 10:03:02.5 +0000 UTC\n",
+		},
+		{
+			format:   formatRFC3339,
+			expected: "1945-05-09T10:03:02.5Z\n",
+		},
+		{
+			format:   formatUnix,
+			expected: "-777823017.500000000\n",
+		},
+	} {
+		t.Run(tt.format, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := writeReport(&out, tt.format, r); err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != tt.expected {
+				t.Fatalf("invalid output %q, expected %q", out.String(), tt.expected)
+			}
+		})
+	}
+
+	t.Run(formatJSON, func(t *testing.T) {
+		var out bytes.Buffer
+		if err := writeReport(&out, formatJSON, r); err != nil {
+			t.Fatal(err)
+		}
+
+		var decoded report
+		if err := json.Unmarshal(out.Bytes(), &decoded); err != nil {
+			t.Fatal(err)
+		}
+
+		if decoded.Offset != 2500*time.Millisecond || len(decoded.Servers) != 2 {
+			t.Fatalf("invalid report %+v", decoded)
+		}
+
+		a, b := decoded.Servers[0], decoded.Servers[1]
+		if a.ReferenceID != "GPS" || a.Leap != "add_second" || a.RTT != 10*time.Millisecond || !a.Time.Equal(serverTime) {
+			t.Fatalf("invalid server report %+v", a)
+		}
+		if b.Error != "timeout" || b.Time != nil {
+			t.Fatalf("invalid failed server report %+v", b)
+		}
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		if err := writeReport(&bytes.Buffer{}, "xml", r); !errors.Is(err, ErrInvalidConfig) {
+			t.Fatalf("unexpected error %v", err)
+		}
+	})
+}
+
+func TestReferenceID(t *testing.T) {
+	if id := referenceID(1, 0x50505300); id != "PPS" {
+		t.Fatalf("invalid reference id %q of stratum 1", id)
+	}
+	if id := referenceID(2, 0xC0A80001); id != "192.168.0.1" {
+		t.Fatalf("invalid reference id %q of stratum 2", id)
+	}
+}
+
+func TestFormatUnixTime(t *testing.T) {
+	for _, tt := range [...]struct {
+		time     time.Time
+		expected string
+	}{
+		{time.Unix(1, 5), "1.000000005"},
+		{time.Unix(0, 0), "0.000000000"},
+		{time.Unix(0, -500), "-0.000000500"},
+		{time.Unix(-2, 0), "-2.000000000"},
+	} {
+		if result := formatUnixTime(tt.time); result != tt.expected {
+			t.Fatalf("invalid unix time %q, expected %q", result, tt.expected)
+		}
+	}
+}
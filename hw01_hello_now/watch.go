@@ -0,0 +1,274 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+var (
+	watchMode      bool
+	watchInterval  time.Duration
+	driftThreshold time.Duration
+	metricsAddr    string
+)
+
+func init() {
+	flag.BoolVar(&watchMode, "watch", false, "poll NTP servers periodically and monitor clock drift")
+	flag.DurationVar(&watchInterval, "interval", time.Minute, "poll interval in watch mode")
+	flag.DurationVar(&driftThreshold, "drift-threshold", 100*time.Millisecond, "warn when clock offset exceeds threshold in watch mode")
+	flag.StringVar(&metricsAddr, "metrics-addr", ":9123", "address of /metrics HTTP endpoint in watch mode, empty disables it")
+}
+
+// serverMetrics contains the latest measurements of single NTP server.
+type serverMetrics struct {
+	up             bool
+	offset         time.Duration
+	rtt            time.Duration
+	stratum        uint8
+	rootDispersion time.Duration
+}
+
+// metrics contains the latest measurements and counters collected in watch mode.
+type metrics struct {
+	mu             sync.RWMutex
+	servers        []string
+	perServer      map[string]serverMetrics
+	offset         time.Duration
+	synced         bool
+	lastPoll       time.Time
+	polls          uint64
+	pollErrors     uint64
+	driftWarnings  uint64
+	driftThreshold time.Duration
+}
+
+// newMetrics creates metrics storage for given servers.
+func newMetrics(servers []string, threshold time.Duration) *metrics {
+	return &metrics{
+		servers:        servers,
+		perServer:      make(map[string]serverMetrics, len(servers)),
+		driftThreshold: threshold,
+	}
+}
+
+// record stores results of single poll, returns true when combined offset exceeds drift threshold.
+func (m *metrics) record(now time.Time, results []hostResponse, offset time.Duration, err error) (drifted bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.polls++
+	m.lastPoll = now
+
+	for _, res := range results {
+		sm := serverMetrics{up: res.err == nil}
+		if res.response != nil {
+			sm.offset = res.response.ClockOffset
+			sm.rtt = res.response.RTT
+			sm.stratum = res.response.Stratum
+			sm.rootDispersion = res.response.RootDispersion
+		}
+		m.perServer[res.host] = sm
+	}
+
+	if err != nil {
+		m.pollErrors++
+		m.synced = false
+
+		return false
+	}
+
+	m.offset = offset
+	m.synced = true
+
+	if offset > m.driftThreshold || -offset > m.driftThreshold {
+		m.driftWarnings++
+
+		return true
+	}
+
+	return false
+}
+
+// WriteTo writes metrics in Prometheus text exposition format.
+func (m *metrics) WriteTo(w io.Writer) (int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	pw := &promWriter{w: w}
+
+	pw.header("ntp_offset_seconds", "gauge", "Clock offset agreed by majority of NTP servers.")
+	pw.sample("ntp_offset_seconds", "", m.offset.Seconds())
+	pw.header("ntp_synced", "gauge", "Whether the last poll found majority of NTP servers.")
+	pw.sample("ntp_synced", "", boolToFloat(m.synced))
+	pw.header("ntp_drift_threshold_seconds", "gauge", "Configured clock drift warning threshold.")
+	pw.sample("ntp_drift_threshold_seconds", "", m.driftThreshold.Seconds())
+	pw.header("ntp_last_poll_timestamp_seconds", "gauge", "Unix time of the last poll.")
+	pw.sample("ntp_last_poll_timestamp_seconds", "", float64(m.lastPoll.UnixNano())/float64(time.Second))
+	pw.header("ntp_polls_total", "counter", "Count of NTP pool polls.")
+	pw.sample("ntp_polls_total", "", float64(m.polls))
+	pw.header("ntp_poll_errors_total", "counter", "Count of polls without agreed offset.")
+	pw.sample("ntp_poll_errors_total", "", float64(m.pollErrors))
+	pw.header("ntp_drift_warnings_total", "counter", "Count of polls with offset over drift threshold.")
+	pw.sample("ntp_drift_warnings_total", "", float64(m.driftWarnings))
+
+	for _, metric := range [...]struct {
+		name, help string
+		value      func(sm serverMetrics) float64
+	}{
+		{"ntp_server_up", "Whether NTP server answered valid response.", func(sm serverMetrics) float64 { return boolToFloat(sm.up) }},
+		{"ntp_server_offset_seconds", "Clock offset reported by NTP server.", func(sm serverMetrics) float64 { return sm.offset.Seconds() }},
+		{"ntp_server_rtt_seconds", "Round-trip time to NTP server.", func(sm serverMetrics) float64 { return sm.rtt.Seconds() }},
+		{"ntp_server_stratum", "Stratum of NTP server.", func(sm serverMetrics) float64 { return float64(sm.stratum) }},
+		{"ntp_server_root_dispersion_seconds", "Root dispersion of NTP server.", func(sm serverMetrics) float64 { return sm.rootDispersion.Seconds() }},
+	} {
+		pw.header(metric.name, "gauge", metric.help)
+		for _, server := range m.servers {
+			if sm, ok := m.perServer[server]; ok {
+				pw.sample(metric.name, fmt.Sprintf("{server=%q}", server), metric.value(sm))
+			}
+		}
+	}
+
+	return pw.n, pw.err
+}
+
+// ServeHTTP implements http.Handler.
+func (m *metrics) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	if _, err := m.WriteTo(w); err != nil {
+		log.Printf("unable to write metrics, %s", err)
+	}
+}
+
+// promWriter writes Prometheus text lines and keeps the first error.
+type promWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (pw *promWriter) printf(format string, args ...interface{}) {
+	if pw.err != nil {
+		return
+	}
+
+	n, err := fmt.Fprintf(pw.w, format, args...)
+	pw.n += int64(n)
+	pw.err = err
+}
+
+func (pw *promWriter) header(name, kind, help string) {
+	pw.printf("# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+func (pw *promWriter) sample(name, labels string, value float64) {
+	pw.printf("%s%s %g\n", name, labels, value)
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+
+	return 0
+}
+
+// poll makes single request to the pool and records results.
+func poll(cfg config, m *metrics, now func() time.Time) {
+	results := queryPool(cfg.Servers, cfg.queryOptions())
+	for _, res := range results {
+		if res.err != nil {
+			log.Printf("skip NTP host %s, %s", res.host, res.err)
+		}
+	}
+
+	offset, err := combineOffset(results)
+	if err != nil {
+		log.Printf("unable get NTP time, %s", err)
+	}
+
+	if m.record(now(), results, offset, err) {
+		log.Printf("WARNING: clock drift %s exceeds threshold %s", offset, m.driftThreshold)
+	} else if err == nil {
+		log.Printf("clock offset %s", offset)
+	}
+}
+
+// watch polls NTP servers every interval until context is done.
+func watch(ctx context.Context, cfg config, interval time.Duration, m *metrics, now func() time.Time) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		poll(cfg, m, now)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runWatch starts metrics endpoint and polls NTP servers until SIGINT or SIGTERM received.
+func runWatch(cfg config, now func() time.Time) error {
+	if watchInterval <= 0 {
+		return fmt.Errorf("%w: interval must be positive, given %s", ErrInvalidConfig, watchInterval)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	m := newMetrics(cfg.Servers, driftThreshold)
+
+	chServerErr := make(chan error, 1)
+	if metricsAddr != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", m)
+		server := &http.Server{Addr: metricsAddr, Handler: mux}
+
+		go func() {
+			chServerErr <- server.ListenAndServe()
+		}()
+		defer func() {
+			if err := server.Close(); err != nil {
+				log.Printf("unable to close metrics server, %s", err)
+			}
+		}()
+
+		log.Printf("serve metrics on %s/metrics", metricsAddr)
+	}
+
+	chWatchTerm := make(chan struct{})
+	go func() {
+		defer close(chWatchTerm)
+		watch(ctx, cfg, watchInterval, m, now)
+	}()
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
+
+	select {
+	case sign := <-sigChan:
+		log.Printf("signal %s recv, terminate...", sign)
+		cancel()
+		<-chWatchTerm
+
+		return nil
+	case err := <-chServerErr:
+		cancel()
+		<-chWatchTerm
+
+		return fmt.Errorf("metrics server failed, %w", err)
+	}
+}
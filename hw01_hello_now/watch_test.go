@@ -0,0 +1,92 @@
+package main
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/beevik/ntp"
+
+	"github.com/PrideSt/otus-golang/hw01_hello_now/internal/fakentp"
+)
+
+func TestMetricsRecord(t *testing.T) {
+	m := newMetrics([]string{"a", "b"}, 100*time.Millisecond)
+	now := time.Unix(1000, 0)
+
+	results := []hostResponse{
+		{host: "a", response: &ntp.Response{ClockOffset: 50 * time.Millisecond, RTT: 10 * time.Millisecond, Stratum: 2}},
+		{host: "b", err: errors.New("timeout")},
+	}
+
+	if m.record(now, results, 50*time.Millisecond, nil) {
+		t.Fatal("offset under threshold reported as drift")
+	}
+	if !m.record(now, results, -150*time.Millisecond, nil) {
+		t.Fatal("negative offset over threshold not reported as drift")
+	}
+	if m.record(now, results, 0, ErrNoMajority) {
+		t.Fatal("failed poll reported as drift")
+	}
+
+	if m.polls != 3 || m.pollErrors != 1 || m.driftWarnings != 1 || m.synced {
+		t.Fatalf("invalid counters %d/%d/%d, synced %t", m.polls, m.pollErrors, m.driftWarnings, m.synced)
+	}
+}
+
+func TestMetricsHandler(t *testing.T) {
+	m := newMetrics([]string{"a", "b"}, time.Second)
+	m.record(time.Unix(1000, 0), []hostResponse{
+		{host: "a", response: &ntp.Response{ClockOffset: 2 * time.Second, RTT: 10 * time.Millisecond, Stratum: 2, RootDispersion: time.Millisecond}},
+		{host: "b", err: errors.New("timeout")},
+	}, 2*time.Second, nil)
+
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	body, err := ioutil.ReadAll(rec.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, line := range [...]string{
+		"# TYPE ntp_offset_seconds gauge",
+		"ntp_offset_seconds 2",
+		"ntp_synced 1",
+		"ntp_polls_total 1",
+		"ntp_drift_warnings_total 1",
+		"ntp_last_poll_timestamp_seconds 1000",
+		`ntp_server_up{server="a"} 1`,
+		`ntp_server_up{server="b"} 0`,
+		`ntp_server_rtt_seconds{server="a"} 0.01`,
+		`ntp_server_stratum{server="a"} 2`,
+		`ntp_server_root_dispersion_seconds{server="a"} 0.001`,
+	} {
+		if !strings.Contains(string(body), line+"\n") {
+			t.Fatalf("line %q not found in:\n%s", line, body)
+		}
+	}
+}
+
+func TestPoll(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Timeout = duration(200 * time.Millisecond)
+	cfg.Servers = startServers(t, fakentp.Options{Offset: time.Second}, fakentp.Options{LossRate: 1})
+	m := newMetrics(cfg.Servers, 500*time.Millisecond)
+
+	now := time.Unix(1000, 0)
+	poll(cfg, m, func() time.Time { return now })
+
+	if !m.synced || m.driftWarnings != 1 || !m.lastPoll.Equal(now) {
+		t.Fatalf("invalid state after poll, synced %t, warnings %d, last poll %s", m.synced, m.driftWarnings, m.lastPoll)
+	}
+	if diff := m.offset - time.Second; diff > 50*time.Millisecond || diff < -50*time.Millisecond {
+		t.Fatalf("invalid offset %s", m.offset)
+	}
+	if !m.perServer[cfg.Servers[0]].up || m.perServer[cfg.Servers[1]].up {
+		t.Fatalf("invalid servers state %+v", m.perServer)
+	}
+}
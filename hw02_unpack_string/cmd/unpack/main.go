@@ -0,0 +1,210 @@
+// Command unpack decodes (or encodes with -pack) strings like "a4bc2d5e" <=> "aaaabccddddde".
+//
+// Usage:
+//
+//	unpack [-pack] [-lines] [-max-size bytes] [file ...]
+//
+// Input is read from files or stdin when no files given or file is "-".
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/PrideSt/otus-golang/hw02_unpack_string/internal/repeatgroup"
+)
+
+const (
+	// ErrorCodeIO happens when input can't be read or output can't be written.
+	ErrorCodeIO = iota + 1
+	// ErrorCodeUsage happens when flags are invalid.
+	ErrorCodeUsage
+	// ErrorCodeSizeLimit happens when output is longer then -max-size.
+	ErrorCodeSizeLimit
+	// ErrorCodeInvalidUTF8 happens when input for -pack isn't valid utf8.
+	ErrorCodeInvalidUTF8
+	// ErrorCodeInvalidFormat happens on malformed input of unknown kind.
+	ErrorCodeInvalidFormat
+)
+
+// Exit codes of malformed input, they match repeatgroup.ErrorKind.
+const (
+	ErrorCodeLeadingDigit = 10 + iota
+	ErrorCodeZeroCount
+	ErrorCodeCountOverflow
+	ErrorCodeGroupTooLong
+	ErrorCodeTrailingJoiner
+)
+
+var kindCodes = map[repeatgroup.ErrorKind]int{
+	repeatgroup.KindLeadingDigit:   ErrorCodeLeadingDigit,
+	repeatgroup.KindZeroCount:      ErrorCodeZeroCount,
+	repeatgroup.KindCountOverflow:  ErrorCodeCountOverflow,
+	repeatgroup.KindGroupTooLong:   ErrorCodeGroupTooLong,
+	repeatgroup.KindTrailingJoiner: ErrorCodeTrailingJoiner,
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// converter transforms input by Parser and Unpacker or by Pack.
+type converter struct {
+	pack    bool
+	maxSize int
+	parser  repeatgroup.Parser
+}
+
+func (c converter) convert(input string) (string, error) {
+	if c.pack {
+		packed, err := repeatgroup.Pack(input)
+		if err != nil {
+			return "", err
+		}
+		if c.maxSize > 0 && len(packed) > c.maxSize {
+			return "", &repeatgroup.SizeLimitError{Size: len(packed), Limit: c.maxSize}
+		}
+
+		return packed, nil
+	}
+
+	unpacker, err := c.parser.ParseString(input)
+	if err != nil {
+		return "", err
+	}
+
+	return unpacker.Unpack()
+}
+
+// run converts files given in args or stdin, writes result to stdout and errors to stderr, returns exit code.
+// In line mode every line is converted separately and malformed lines don't stop conversion.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("unpack", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	pack := flags.Bool("pack", false, "encode input instead of decoding")
+	lines := flags.Bool("lines", false, "convert every line separately")
+	maxSize := flags.Int("max-size", 0, "limit of output size in bytes, per line in line mode, 0 means unlimited")
+
+	if err := flags.Parse(args); err != nil {
+		return ErrorCodeUsage
+	}
+
+	parser, err := repeatgroup.NewParser(repeatgroup.ParserOptions{MaxSize: *maxSize})
+	if err != nil {
+		_, _ = fmt.Fprintln(stderr, err)
+		return ErrorCodeUsage
+	}
+	c := converter{pack: *pack, maxSize: *maxSize, parser: parser}
+
+	files := flags.Args()
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+
+	out := bufio.NewWriter(stdout)
+	code := 0
+	for _, name := range files {
+		fileCode := convertFile(c, name, *lines, stdin, out, stderr)
+		if code == 0 {
+			code = fileCode
+		}
+		if fileCode == ErrorCodeIO {
+			break
+		}
+	}
+
+	if err := out.Flush(); err != nil {
+		_, _ = fmt.Fprintf(stderr, "unable to write output, %s\n", err)
+		return ErrorCodeIO
+	}
+
+	return code
+}
+
+// convertFile converts single file, "-" means stdin, returns exit code of the first error.
+func convertFile(c converter, name string, lines bool, stdin io.Reader, out *bufio.Writer, stderr io.Writer) int {
+	in := stdin
+	if name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			_, _ = fmt.Fprintf(stderr, "unable to open input, %s\n", err)
+			return ErrorCodeIO
+		}
+		defer f.Close()
+		in = f
+	}
+
+	if !lines {
+		data, err := io.ReadAll(in)
+		if err != nil {
+			_, _ = fmt.Fprintf(stderr, "%s: unable to read input, %s\n", name, err)
+			return ErrorCodeIO
+		}
+
+		return convertChunk(c, name, string(data), "", out, stderr)
+	}
+
+	code := 0
+	r := bufio.NewReader(in)
+	for lineNum := 1; ; lineNum++ {
+		line, err := r.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			_, _ = fmt.Fprintf(stderr, "%s:%d: unable to read input, %s\n", name, lineNum, err)
+			return ErrorCodeIO
+		}
+		if line == "" {
+			return code
+		}
+
+		eol := ""
+		if strings.HasSuffix(line, "\n") {
+			line, eol = line[:len(line)-1], "\n"
+		}
+
+		lineCode := convertChunk(c, fmt.Sprintf("%s:%d", name, lineNum), line, eol, out, stderr)
+		if code == 0 {
+			code = lineCode
+		}
+		if lineCode == ErrorCodeIO {
+			return lineCode
+		}
+	}
+}
+
+// convertChunk converts input and writes result followed by eol, errors are prefixed by location.
+func convertChunk(c converter, location, input, eol string, out *bufio.Writer, stderr io.Writer) int {
+	result, err := c.convert(input)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "%s: %s\n", location, err)
+
+		var pe *repeatgroup.ParseError
+		switch {
+		case errors.As(err, &pe):
+			_, _ = fmt.Fprintln(stderr, pe.Snippet())
+			if code, ok := kindCodes[pe.Kind]; ok {
+				return code
+			}
+			return ErrorCodeInvalidFormat
+		case errors.Is(err, repeatgroup.ErrSizeLimit):
+			return ErrorCodeSizeLimit
+		case errors.Is(err, repeatgroup.ErrInvalidUTF8):
+			return ErrorCodeInvalidUTF8
+		case errors.Is(err, repeatgroup.ErrGroupTooLong):
+			return ErrorCodeGroupTooLong
+		default:
+			return ErrorCodeInvalidFormat
+		}
+	}
+
+	if _, err := out.WriteString(result + eol); err != nil {
+		_, _ = fmt.Fprintf(stderr, "unable to write output, %s\n", err)
+		return ErrorCodeIO
+	}
+
+	return 0
+}
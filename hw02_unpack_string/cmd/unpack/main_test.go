@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "packed.txt")
+	require.NoError(t, os.WriteFile(file, []byte("b3\nc2"), 0o600))
+
+	for _, tt := range [...]struct {
+		name   string
+		args   []string
+		stdin  string
+		stdout string
+		stderr string
+		code   int
+	}{
+		{
+			name:   `Unpack stdin`,
+			stdin:  `a4bc2d5e`,
+			stdout: `aaaabccddddde`,
+		},
+		{
+			name:   `Pack stdin`,
+			args:   []string{"-pack"},
+			stdin:  `aaaabccddddde`,
+			stdout: `a4bc2d5e`,
+		},
+		{
+			name:   `Files and stdin`,
+			args:   []string{file, "-"},
+			stdin:  `a2`,
+			stdout: "bbb\nccaa",
+		},
+		{
+			name:   `Lines`,
+			args:   []string{"-lines"},
+			stdin:  "a2\nb3\n",
+			stdout: "aa\nbbb\n",
+		},
+		{
+			name:   `Lines continue after malformed one`,
+			args:   []string{"-lines"},
+			stdin:  "a2\n3b\nc2",
+			stdout: "aa\ncc",
+			stderr: "-:2: invalid format at offset 0 (rune 0), digit can't be the first symbol\n3b\n^\n",
+			code:   ErrorCodeLeadingDigit,
+		},
+		{
+			name:   `Leading digit`,
+			stdin:  `ab45`,
+			stderr: "-: invalid format at offset 3 (rune 3), digit can't be the first symbol\nab45\n   ^\n",
+			code:   ErrorCodeLeadingDigit,
+		},
+		{
+			name:  `Trailing joiner`,
+			stdin: "a\u200D",
+			code:  ErrorCodeTrailingJoiner,
+		},
+		{
+			name:   `Max size`,
+			args:   []string{"-max-size", "3"},
+			stdin:  `a4`,
+			stderr: "-: expanded size limit exceeded: 4 bytes, limit 3 bytes\n",
+			code:   ErrorCodeSizeLimit,
+		},
+		{
+			name:   `Max size of packed output`,
+			args:   []string{"-pack", "-max-size", "1"},
+			stdin:  `aa`,
+			stderr: "-: expanded size limit exceeded: 2 bytes, limit 1 bytes\n",
+			code:   ErrorCodeSizeLimit,
+		},
+		{
+			name:  `Pack invalid utf8`,
+			args:  []string{"-pack"},
+			stdin: "a\xff",
+			code:  ErrorCodeInvalidUTF8,
+		},
+		{
+			name:  `Pack too long group`,
+			args:  []string{"-pack"},
+			stdin: "a" + strings.Repeat("\u0301", 600),
+			code:  ErrorCodeGroupTooLong,
+		},
+		{
+			name: `Missing file`,
+			args: []string{filepath.Join(dir, "missing.txt")},
+			code: ErrorCodeIO,
+		},
+		{
+			name: `Negative max size`,
+			args: []string{"-max-size", "-1"},
+			code: ErrorCodeUsage,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := run(tt.args, strings.NewReader(tt.stdin), &stdout, &stderr)
+
+			require.Equal(t, tt.code, code, "stderr: %s", stderr.String())
+			require.Equal(t, tt.stdout, stdout.String())
+			if tt.stderr != "" || tt.code == 0 {
+				require.Equal(t, tt.stderr, stderr.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package hw02_unpack_string //nolint:golint,stylecheck
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/PrideSt/otus-golang/hw02_unpack_string/internal/repeatgroup"
+)
+
+// FuzzUnpack checks that Unpack and UnpackReader agree and Pack reverts Unpack, run it by
+//
+//	go test -fuzz=FuzzUnpack .
+//
+// Seed corpus is in testdata/fuzz/FuzzUnpack, go test minimises failing input and saves them there too,
+// so plain go test runs them as regression cases. Output is compared with reference implementation
+// by FuzzParseString of internal/repeatgroup.
+func FuzzUnpack(f *testing.F) {
+	f.Fuzz(func(t *testing.T, input string) {
+		result, err := Unpack(input)
+		var out bytes.Buffer
+		streamErr := UnpackReader(strings.NewReader(input), &out)
+		if err != nil {
+			require.Error(t, streamErr, "unpack error %v", err)
+
+			return
+		}
+		require.NoError(t, streamErr)
+		require.Equal(t, result, out.String())
+
+		if !utf8.ValidString(input) {
+			return
+		}
+
+		// unpacked string is packed back not necessarily to input, but to equivalent string
+		packed, err := Pack(result)
+		var gle *GroupTooLongError
+		if errors.As(err, &gle) {
+			// repeated group is glued into one cluster which Unpack wouldn't accept
+			require.Greater(t, gle.Size, repeatgroup.MaxGroupSize)
+			require.LessOrEqual(t, gle.Offset+gle.Size, len(result))
+
+			return
+		}
+		require.NoError(t, err)
+		unpacked, err := Unpack(packed)
+		require.NoError(t, err)
+		require.Equal(t, result, unpacked, "packed %q", packed)
+	})
+}
@@ -1,12 +1,17 @@
 module github.com/PrideSt/otus-golang/hw02_unpack_string
 
-go 1.14
+go 1.18
+
+require (
+	github.com/rivo/uniseg v0.4.7
+	github.com/stretchr/testify v1.6.1
+)
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/kr/text v0.2.0 // indirect
 	github.com/niemeyer/pretty v0.0.0-20200227124842-a10e7caefd8e // indirect
-	github.com/stretchr/testify v1.6.1
+	github.com/pmezard/go-difflib v1.0.0 // indirect
 	gopkg.in/check.v1 v1.0.0-20200227125254-8fa46927fb4f // indirect
 	gopkg.in/yaml.v3 v3.0.0-20200615113413-eeeca48fe776 // indirect
 )
@@ -0,0 +1,58 @@
+package reader
+
+import (
+	"bufio"
+	"io"
+	"unicode/utf8"
+)
+
+// Stream is Reader over io.Reader, it doesn't hold whole input in memory.
+type Stream struct {
+	reader *bufio.Reader
+	offset int
+	err    error
+}
+
+// NewStream returns new Stream instance reading from r.
+func NewStream(r io.Reader) *Stream {
+	return &Stream{reader: bufio.NewReader(r)}
+}
+
+// GetNext returns next rune from underlying reader.
+func (s *Stream) GetNext() (rune, int) {
+	rn, sz, err := s.reader.ReadRune()
+	if err != nil {
+		s.setErr(err)
+
+		return utf8.RuneError, s.offset
+	}
+	s.offset += sz
+
+	return rn, s.offset
+}
+
+// IsEOF returns true when underlying reader has no more data or fails.
+func (s *Stream) IsEOF() bool {
+	if s.err != nil {
+		return true
+	}
+
+	if _, err := s.reader.Peek(1); err != nil {
+		s.setErr(err)
+
+		return true
+	}
+
+	return false
+}
+
+// Err returns the first read error, io.EOF is not an error.
+func (s *Stream) Err() error {
+	return s.err
+}
+
+func (s *Stream) setErr(err error) {
+	if err != io.EOF && s.err == nil {
+		s.err = err
+	}
+}
@@ -0,0 +1,138 @@
+package repeatgroup
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/rivo/uniseg"
+)
+
+// snippetContext is a count of runes shown around the wrong part of input in snippet.
+const snippetContext = 20
+
+// ErrorKind classifies malformed input.
+type ErrorKind int
+
+const (
+	// KindLeadingDigit means repeat count without group before it, "3abc" or "a12" in single digit mode.
+	KindLeadingDigit ErrorKind = iota + 1
+	// KindZeroCount means zero repeat count when ZeroCountError mode is on.
+	KindZeroCount
+	// KindCountOverflow means repeat count bigger then math.MaxInt32.
+	KindCountOverflow
+	// KindGroupTooLong means group longer then MaxGroupSize bytes, e.g. letter with thousands of combining marks.
+	KindGroupTooLong
+	// KindTrailingJoiner means input ending with zero width joiner or non-joiner, it has nothing to join.
+	KindTrailingJoiner
+)
+
+// String implements fmt.Stringer.
+func (k ErrorKind) String() string {
+	switch k {
+	case KindLeadingDigit:
+		return "digit can't be the first symbol"
+	case KindZeroCount:
+		return "zero repeat count isn't allowed"
+	case KindCountOverflow:
+		return "repeat count is too big"
+	case KindGroupTooLong:
+		return fmt.Sprintf("group is longer then %d bytes", MaxGroupSize)
+	case KindTrailingJoiner:
+		return "input can't end with joiner"
+	default:
+		return fmt.Sprintf("unknown error kind %d", int(k))
+	}
+}
+
+// ParseError describes malformed input, it points to the wrong part of input.
+type ParseError struct {
+	Kind   ErrorKind
+	Offset int    // byte offset of the wrong part
+	Index  int    // rune index of the wrong part
+	Size   int    // size of the wrong part in bytes
+	Input  string // whole input, it is empty when input is read from stream
+}
+
+// Error implements error.
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("invalid format at offset %d (rune %d), %s", e.Offset, e.Index, e.Kind)
+}
+
+// Snippet returns input with carets under the wrong part, long input is shortened around them.
+// It returns empty string when input is unknown.
+//
+//	a2b34c
+//	    ^
+func (e *ParseError) Snippet() string {
+	if e.Input == "" || e.Offset < 0 || e.Size < 0 || e.Offset+e.Size > len(e.Input) {
+		return ""
+	}
+
+	before := e.Input[:e.Offset]
+	wrong := e.Input[e.Offset : e.Offset+e.Size]
+	after := e.Input[e.Offset+e.Size:]
+
+	if utf8.RuneCountInString(before) > snippetContext {
+		runes := []rune(before)
+		before = "..." + string(runes[len(runes)-snippetContext:])
+	}
+	if utf8.RuneCountInString(after) > snippetContext {
+		after = string([]rune(after)[:snippetContext]) + "..."
+	}
+
+	before, wrong, after = printable(before), printable(wrong), printable(after)
+
+	carets := uniseg.StringWidth(wrong)
+	if carets == 0 {
+		carets = 1
+	}
+
+	return before + wrong + after + "\n" +
+		strings.Repeat(" ", uniseg.StringWidth(before)) + strings.Repeat("^", carets)
+}
+
+// printable replaces control symbols, so they don't break snippet lines.
+func printable(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return '·'
+		}
+
+		return r
+	}, s)
+}
+
+// SizeLimitError happens when expanded string is longer then limit.
+type SizeLimitError struct {
+	Size  int // expanded size in bytes, parser stops at the first group over limit, so it may be less then the whole size
+	Limit int // limit in bytes
+}
+
+// Error implements error.
+func (e *SizeLimitError) Error() string {
+	return fmt.Sprintf("%s: %d bytes, limit %d bytes", ErrSizeLimit, e.Size, e.Limit)
+}
+
+// Is makes errors.Is(err, ErrSizeLimit) true.
+func (e *SizeLimitError) Is(target error) bool {
+	return target == ErrSizeLimit //nolint:errorlint
+}
+
+// GroupTooLongError happens when Pack input contains group longer then MaxGroupSize bytes,
+// parser would reject encoded string, so it can't be packed.
+type GroupTooLongError struct {
+	Offset int // byte offset of group in input
+	Size   int // size of group in bytes
+}
+
+// Error implements error.
+func (e *GroupTooLongError) Error() string {
+	return fmt.Sprintf("%s: %d bytes at offset %d, limit %d bytes", ErrGroupTooLong, e.Size, e.Offset, MaxGroupSize)
+}
+
+// Is makes errors.Is(err, ErrGroupTooLong) true.
+func (e *GroupTooLongError) Is(target error) bool {
+	return target == ErrGroupTooLong //nolint:errorlint
+}
@@ -0,0 +1,165 @@
+package repeatgroup
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseError(t *testing.T) {
+	for _, tt := range [...]struct {
+		name    string
+		opts    ParserOptions
+		input   string
+		kind    ErrorKind
+		message string
+		snippet string
+	}{
+		{
+			name:    `Leading digit`,
+			input:   `3abc`,
+			kind:    KindLeadingDigit,
+			message: `invalid format at offset 0 (rune 0), digit can't be the first symbol`,
+			snippet: "3abc\n^",
+		},
+		{
+			name:    `Second digit`,
+			input:   `a2b34c`,
+			kind:    KindLeadingDigit,
+			message: `invalid format at offset 4 (rune 4), digit can't be the first symbol`,
+			snippet: "a2b34c\n    ^",
+		},
+		{
+			name:    `Multi byte runes before error`,
+			input:   "ы2か\u200D33",
+			kind:    KindLeadingDigit,
+			message: `invalid format at offset 10 (rune 5), digit can't be the first symbol`,
+			snippet: "ы2か\u200D33\n     ^",
+		},
+		{
+			name:    `Zero count underlines whole count`,
+			opts:    ParserOptions{MultiDigitCount: true, ZeroCount: ZeroCountError},
+			input:   `ab000c`,
+			kind:    KindZeroCount,
+			message: `invalid format at offset 2 (rune 2), zero repeat count isn't allowed`,
+			snippet: "ab000c\n  ^^^",
+		},
+		{
+			name:    `Count overflow`,
+			opts:    ParserOptions{MultiDigitCount: true},
+			input:   `a99999999999`,
+			kind:    KindCountOverflow,
+			message: `invalid format at offset 1 (rune 1), repeat count is too big`,
+			snippet: "a99999999999\n ^^^^^^^^^^",
+		},
+		{
+			name:    `Trailing joiner`,
+			input:   "ab\u200D",
+			kind:    KindTrailingJoiner,
+			message: `invalid format at offset 2 (rune 2), input can't end with joiner`,
+			snippet: "ab\u200D\n  ^",
+		},
+		{
+			name:    `Trailing non-joiner of escaped group`,
+			input:   "a\\b\u200C",
+			kind:    KindTrailingJoiner,
+			message: `invalid format at offset 3 (rune 3), input can't end with joiner`,
+			snippet: "a\\b\u200C\n   ^",
+		},
+		{
+			name:    `Control symbols`,
+			input:   "a\n\t55",
+			kind:    KindLeadingDigit,
+			message: `invalid format at offset 4 (rune 4), digit can't be the first symbol`,
+			snippet: "a··55\n    ^",
+		},
+		{
+			name:    `Long input`,
+			input:   strings.Repeat("a", 30) + "2" + "3" + strings.Repeat("b", 30),
+			kind:    KindLeadingDigit,
+			message: `invalid format at offset 31 (rune 31), digit can't be the first symbol`,
+			snippet: "..." + strings.Repeat("a", 19) + "23" + strings.Repeat("b", 20) + "...\n" +
+				strings.Repeat(" ", 23) + "^",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewParser(tt.opts)
+			require.NoError(t, err)
+
+			_, err = p.ParseString(tt.input)
+
+			var pe *ParseError
+			require.True(t, errors.As(fmt.Errorf("wrapped, %w", err), &pe), "unexpected error %v", err)
+			require.Equal(t, tt.kind, pe.Kind)
+			require.Equal(t, tt.input, pe.Input)
+			require.EqualError(t, pe, tt.message)
+			require.Equal(t, tt.snippet, pe.Snippet())
+
+			// stream error has the same position, but input is unknown
+			err = p.UnpackReader(strings.NewReader(tt.input), &bytes.Buffer{})
+
+			var streamErr *ParseError
+			require.True(t, errors.As(err, &streamErr), "unexpected error %v", err)
+			require.Equal(t, ParseError{Kind: pe.Kind, Offset: pe.Offset, Index: pe.Index, Size: pe.Size}, *streamErr)
+			require.Empty(t, streamErr.Snippet())
+		})
+	}
+}
+
+func TestGroupTooLong(t *testing.T) {
+	for _, tt := range [...]struct {
+		name   string
+		input  string
+		offset int
+		index  int
+	}{
+		{
+			name:   `Endless combining marks`,
+			input:  "b2a" + strings.Repeat("\u0301", 10_000) + "3",
+			offset: 2,
+			index:  2,
+		},
+		{
+			name:   `Escaped cluster with endless combining marks`,
+			input:  `\` + "5" + strings.Repeat("\u0301", 10_000),
+			offset: 1,
+			index:  1,
+		},
+		{
+			name:   `Endless symbol modifiers`,
+			input:  "a" + strings.Repeat("˯", 10_000),
+			offset: 1 + 511*2,
+			index:  512,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseString(tt.input)
+
+			var pe *ParseError
+			require.True(t, errors.As(err, &pe), "unexpected error %v", err)
+			require.Equal(t, KindGroupTooLong, pe.Kind)
+			require.Equal(t, tt.offset, pe.Offset)
+			require.Equal(t, tt.index, pe.Index)
+			require.Greater(t, pe.Size, 0)
+			require.Contains(t, pe.Error(), "group is longer then 1024 bytes")
+
+			err = UnpackReader(strings.NewReader(tt.input), &bytes.Buffer{})
+			require.True(t, errors.As(err, &pe), "unexpected error %v", err)
+			require.Equal(t, tt.offset, pe.Offset)
+		})
+	}
+
+	t.Run("group of max size", func(t *testing.T) {
+		input := "a" + strings.Repeat("\u0301", (MaxGroupSize-1)/2) + "2"
+		result, err := ParseString(input)
+		require.NoError(t, err)
+
+		unpacked, err := result.Unpack()
+		require.NoError(t, err)
+		require.Equal(t, 2*(len(input)-1), len(unpacked))
+	})
+}
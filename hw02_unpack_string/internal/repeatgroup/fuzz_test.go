@@ -0,0 +1,64 @@
+package repeatgroup
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// FuzzParseString checks parser against referenceUnpack and UnpackReader against ParseString, run it by
+//
+//	go test -fuzz=FuzzParseString ./internal/repeatgroup
+//
+// Reference segmentation is naive, so output is compared with it only for inputs of naiveClass alphabet,
+// the rest of inputs are checked for consistency of parser results only.
+// Seed corpus is in testdata/fuzz/FuzzParseString, go test minimises failing input and saves them there too,
+// so plain go test runs them as regression cases.
+func FuzzParseString(f *testing.F) {
+	f.Fuzz(func(t *testing.T, input string) {
+		gs, err := ParseString(input)
+
+		var out bytes.Buffer
+		streamErr := UnpackReader(strings.NewReader(input), &out)
+
+		expected, comparable, refErr := referenceUnpack(input)
+		if comparable && refErr != nil {
+			var re *referenceError
+			require.True(t, errors.As(refErr, &re))
+
+			var pe *ParseError
+			require.True(t, errors.As(err, &pe), "reference error %v, got %v", refErr, err)
+			require.Equal(t, re.kind, pe.Kind)
+			require.Equal(t, re.offset, pe.Offset)
+			require.Equal(t, input, pe.Input)
+		}
+		if comparable && refErr == nil {
+			require.NoError(t, err)
+		}
+
+		var pe *ParseError
+		if errors.As(err, &pe) {
+			var streamPE *ParseError
+			require.True(t, errors.As(streamErr, &streamPE), "parse error %v, got %v", err, streamErr)
+			require.Equal(t, pe.Kind, streamPE.Kind)
+			require.Equal(t, pe.Offset, streamPE.Offset)
+			require.Equal(t, pe.Index, streamPE.Index)
+
+			return
+		}
+		require.NoError(t, err)
+		require.NoError(t, streamErr)
+
+		result, err := gs.Unpack()
+		require.NoError(t, err)
+		if comparable {
+			require.Equal(t, expected, result)
+		}
+		require.Equal(t, result, out.String())
+		require.Equal(t, len(result), gs.Size())
+		require.Equal(t, len([]rune(result)), gs.(GroupStorage).Index().Len())
+	})
+}
@@ -0,0 +1,94 @@
+package repeatgroup
+
+import (
+	"unicode/utf8"
+
+	"github.com/rivo/uniseg"
+)
+
+// clusterReader splits runes of RuneReader into extended grapheme clusters.
+// @see https://unicode.org/reports/tr29/#Grapheme_Cluster_Boundaries
+type clusterReader struct {
+	reader  RuneReader
+	cluster []byte
+	pending []byte // runes read ahead, they contain at most one complete cluster
+	offsets []int  // reader offsets at the end of every pending rune
+	state   int    // segmenter state at the start of pending runes
+	next    position
+}
+
+// position locates cluster in input.
+type position struct {
+	offset int // byte offset of cluster start
+	index  int // rune index of cluster start
+	size   int // cluster size in input bytes
+}
+
+// end returns byte offset right after cluster.
+func (p position) end() int {
+	return p.offset + p.size
+}
+
+func newClusterReader(reader RuneReader) *clusterReader {
+	return &clusterReader{reader: reader, state: -1}
+}
+
+// IsEOF returns true when all clusters are read.
+func (c *clusterReader) IsEOF() bool {
+	return len(c.pending) == 0 && c.reader.IsEOF()
+}
+
+// GetNext returns next cluster and its position in input,
+// returned slice is valid until the next call.
+func (c *clusterReader) GetNext() ([]byte, position) {
+	// boundary rules look only backward, so the first boundary found is final,
+	// cluster longer then MaxGroupSize is returned incomplete, parser rejects it anyway
+	for !c.reader.IsEOF() && len(c.pending) <= MaxGroupSize {
+		if len(c.pending) > 0 {
+			if _, rest, _, _ := uniseg.FirstGraphemeCluster(c.pending, c.state); len(rest) > 0 {
+				break
+			}
+		}
+
+		r, offset := c.reader.GetNext()
+		c.pending = utf8.AppendRune(c.pending, r)
+		c.offsets = append(c.offsets, offset)
+	}
+
+	cluster, _, _, state := uniseg.FirstGraphemeCluster(c.pending, c.state)
+	c.cluster = append(c.cluster[:0], cluster...)
+	c.state = state
+
+	// invalid bytes are decoded as utf8.RuneError, so size in input is taken from reader offsets
+	runes := utf8.RuneCount(c.cluster)
+	pos := c.next
+	pos.size = c.offsets[runes-1] - pos.offset
+	c.next = position{offset: pos.end(), index: pos.index + runes}
+
+	c.pending = append(c.pending[:0], c.pending[len(c.cluster):]...)
+	c.offsets = append(c.offsets[:0], c.offsets[runes:]...)
+
+	return c.cluster, pos
+}
+
+// asSingleRune returns the only rune of cluster, ok is false when cluster contains several runes.
+func asSingleRune(cluster []byte) (r rune, ok bool) {
+	r, size := utf8.DecodeRune(cluster)
+
+	return r, size > 0 && size == len(cluster)
+}
+
+// startsWithSymbolModifier returns true when cluster starts with Sk symbol. UAX #29 doesn't treat them
+// as extenders, but they modify previous letter, so parser glues them to the previous group.
+func startsWithSymbolModifier(cluster []byte) bool {
+	r, _ := utf8.DecodeRune(cluster)
+
+	return isSymbolModifier(r)
+}
+
+// gluesToDigit returns true when the first cluster of s extends digit written before s.
+func gluesToDigit(s string) bool {
+	cluster, _, _, _ := uniseg.FirstGraphemeClusterInString("0"+s, -1)
+
+	return cluster != "0"
+}
@@ -0,0 +1,103 @@
+package repeatgroup
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/PrideSt/otus-golang/hw02_unpack_string/internal/reader"
+)
+
+// breakTestCase is a line of GraphemeBreakTest.txt.
+type breakTestCase struct {
+	line     int
+	comment  string
+	input    string
+	expected []string
+}
+
+// loadBreakTest parses UCD test file, lines look like "÷ 0020 × 0308 ÷ 0020 ÷	# comment".
+func loadBreakTest(t *testing.T, path string) []breakTestCase {
+	t.Helper()
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	var cases []breakTestCase
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text, comment := scanner.Text(), ""
+		if i := strings.IndexByte(text, '#'); i >= 0 {
+			text, comment = text[:i], strings.TrimSpace(text[i+1:])
+		}
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+
+		tc := breakTestCase{line: line, comment: comment}
+		var input, cluster strings.Builder
+		for _, field := range strings.Fields(text) {
+			switch field {
+			case "÷":
+				if cluster.Len() > 0 {
+					tc.expected = append(tc.expected, cluster.String())
+					cluster.Reset()
+				}
+			case "×":
+			default:
+				cp, err := strconv.ParseUint(field, 16, 32)
+				require.NoError(t, err, "line %d", line)
+				input.WriteRune(rune(cp))
+				cluster.WriteRune(rune(cp))
+			}
+		}
+		tc.input = input.String()
+		cases = append(cases, tc)
+	}
+	require.NoError(t, scanner.Err())
+
+	return cases
+}
+
+func readClusters(r RuneReader) []string {
+	var clusters []string
+	cr := newClusterReader(r)
+	for !cr.IsEOF() {
+		cluster, _ := cr.GetNext()
+		clusters = append(clusters, string(cluster))
+	}
+
+	return clusters
+}
+
+func TestClusterReaderConformance(t *testing.T) {
+	cases := loadBreakTest(t, "testdata/GraphemeBreakTest.txt")
+	require.NotEmpty(t, cases)
+
+	for _, tc := range cases {
+		r := reader.Make([]byte(tc.input), 0)
+		require.Equal(t, tc.expected, readClusters(&r), "line %d: %s", tc.line, tc.comment)
+
+		// stream reader gets input byte by byte
+		stream := reader.NewStream(iotest.OneByteReader(strings.NewReader(tc.input)))
+		require.Equal(t, tc.expected, readClusters(stream), "line %d: %s", tc.line, tc.comment)
+	}
+}
+
+func TestClusterReaderOffsets(t *testing.T) {
+	r := reader.Make([]byte("a\u0301\r\nb"), 0)
+	cr := newClusterReader(&r)
+
+	var offsets []int
+	for !cr.IsEOF() {
+		_, pos := cr.GetNext()
+		offsets = append(offsets, pos.end())
+	}
+	require.Equal(t, []int{3, 5, 6}, offsets)
+}
@@ -3,11 +3,16 @@ package repeatgroup
 import (
 	"bytes"
 	"fmt"
+	"math"
 )
 
 // Unpacker can create string from Unpacker, assembly string from internal view.
 type Unpacker interface {
 	Unpack() (string, error)
+	// UnpackLimit fails with *SizeLimitError instead of expanding string longer then limit bytes.
+	UnpackLimit(limit int) (string, error)
+	// Size returns length of expanded string in bytes.
+	Size() int
 }
 
 // GroupStorage contain all groups.
@@ -34,6 +39,48 @@ func (gs GroupStorage) Unpack() (string, error) {
 	return result.String(), nil
 }
 
+// Size returns length of expanded string in bytes without expanding them,
+// it is math.MaxInt when length overflows int.
+func (gs GroupStorage) Size() int {
+	size := 0
+	for _, gr := range gs.rgs {
+		size = addSize(size, groupSize(len(gr.buffer), gr.repeatCnt))
+	}
+
+	return size
+}
+
+// UnpackLimit converts internal state to string like Unpack, but fails with *SizeLimitError
+// before any allocation when expanded string is longer then limit bytes.
+func (gs GroupStorage) UnpackLimit(limit int) (string, error) {
+	if size := gs.Size(); size > limit {
+		return "", &SizeLimitError{Size: size, Limit: limit}
+	}
+
+	return gs.Unpack()
+}
+
+// groupSize returns size of n bytes repeated cnt times, saturated at math.MaxInt.
+func groupSize(n, cnt int) int {
+	if cnt <= 0 {
+		return 0
+	}
+	if n > math.MaxInt/cnt {
+		return math.MaxInt
+	}
+
+	return n * cnt
+}
+
+// addSize returns sum of sizes, saturated at math.MaxInt.
+func addSize(a, b int) int {
+	if a > math.MaxInt-b {
+		return math.MaxInt
+	}
+
+	return a + b
+}
+
 // AddRepeatGroup new entry to storage.
 func (gs *GroupStorage) addRepeatGroup(rg repeatGroup) (newSize int) {
 	gs.rgs = append(gs.rgs, rg)
@@ -49,11 +96,3 @@ func (gs *GroupStorage) add(b []byte, cnt int) (newSize int) {
 
 	return len(gs.rgs)
 }
-
-// flushBuffer create repeatGroup from buffer and cntm add them to GroupStorage and flush buffer.
-func (gs *GroupStorage) flushBuffer(buffer *bytes.Buffer, cnt int) {
-	if buffer.Len() > 0 {
-		gs.add(buffer.Bytes(), cnt)
-		buffer.Reset()
-	}
-}
@@ -0,0 +1,88 @@
+package repeatgroup
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnpackLimit(t *testing.T) {
+	for _, tt := range [...]struct {
+		name     string
+		input    GroupStorage
+		limit    int
+		size     int
+		expected string
+	}{
+		{
+			name:  `Empty`,
+			input: GroupStorage{},
+		},
+		{
+			name: `Fits limit`,
+			input: GroupStorage{[]repeatGroup{
+				{[]byte("a"), 2},
+				{[]byte("b"), 0},
+				{[]byte("ы"), 3},
+			}},
+			limit:    8,
+			size:     8,
+			expected: "aaыыы",
+		},
+		{
+			name: `Exceeds limit`,
+			input: GroupStorage{[]repeatGroup{
+				{[]byte("a"), 2},
+				{[]byte("ы"), 3},
+			}},
+			limit: 7,
+			size:  8,
+		},
+		{
+			name: `Bomb`,
+			input: GroupStorage{[]repeatGroup{
+				{make([]byte, 1024), math.MaxInt / 1024},
+				{make([]byte, 1024), math.MaxInt / 1024},
+			}},
+			limit: 1 << 20,
+			size:  math.MaxInt, // size saturates instead of overflow
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.size, tt.input.Size())
+
+			result, err := tt.input.UnpackLimit(tt.limit)
+			if tt.size > tt.limit {
+				var sle *SizeLimitError
+				require.True(t, errors.As(err, &sle), "unexpected error %v", err)
+				require.Equal(t, SizeLimitError{Size: tt.size, Limit: tt.limit}, *sle)
+				require.True(t, errors.Is(err, ErrSizeLimit))
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestSizeSaturation(t *testing.T) {
+	require.Equal(t, math.MaxInt, groupSize(math.MaxInt/2, 3))
+	require.Equal(t, math.MaxInt, addSize(math.MaxInt-1, 2))
+	require.Equal(t, 0, groupSize(10, 0))
+}
+
+func TestParserSizeLimitError(t *testing.T) {
+	p, err := NewParser(ParserOptions{MaxSize: 4})
+	require.NoError(t, err)
+
+	_, err = p.ParseString(`ab2c9d`)
+
+	var sle *SizeLimitError
+	require.True(t, errors.As(err, &sle), "unexpected error %v", err)
+	require.Equal(t, SizeLimitError{Size: 12, Limit: 4}, *sle)
+	require.EqualError(t, err, "expanded size limit exceeded: 12 bytes, limit 4 bytes")
+}
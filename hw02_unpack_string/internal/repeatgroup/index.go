@@ -0,0 +1,163 @@
+package repeatgroup
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Index provides random access to runes of expanded string without expanding it.
+// It is immutable and safe for concurrent use.
+type Index struct {
+	groups []indexedGroup
+	// prefix[i] is count of runes before group i, the last element is count of all runes
+	prefix []int
+}
+
+// indexedGroup is a repeat group with decoded runes, groups repeated zero times are skipped.
+type indexedGroup struct {
+	buffer    []byte
+	runes     []rune
+	repeatCnt int
+}
+
+// Index builds prefix sum index over groups, it takes O(len(groups)) time.
+// Prefix sums saturate at math.MaxInt like GroupStorage.Size, so runes after math.MaxInt-th one aren't indexed.
+func (gs GroupStorage) Index() *Index {
+	idx := &Index{prefix: make([]int, 1, len(gs.rgs)+1)}
+
+	for _, gr := range gs.rgs {
+		runes := []rune(string(gr.buffer))
+		if gr.repeatCnt <= 0 || len(runes) == 0 {
+			continue
+		}
+
+		idx.groups = append(idx.groups, indexedGroup{gr.buffer, runes, gr.repeatCnt})
+		idx.prefix = append(idx.prefix, addSize(idx.Len(), groupSize(len(runes), gr.repeatCnt)))
+	}
+
+	return idx
+}
+
+// Len returns count of runes in expanded string.
+func (idx *Index) Len() int {
+	return idx.prefix[len(idx.prefix)-1]
+}
+
+// locate returns group containing i-th rune and index of rune inside expanded group.
+func (idx *Index) locate(i int) (group, inGroup int) {
+	// the first group which ends after i
+	group = sort.Search(len(idx.groups), func(g int) bool {
+		return idx.prefix[g+1] > i
+	})
+
+	return group, i - idx.prefix[group]
+}
+
+// At returns i-th rune of expanded string, it panics when i is out of range [0, Len()).
+func (idx *Index) At(i int) rune {
+	if i < 0 || i >= idx.Len() {
+		panic(fmt.Sprintf("repeatgroup: index %d out of range [0:%d]", i, idx.Len()))
+	}
+
+	group, inGroup := idx.locate(i)
+	runes := idx.groups[group].runes
+
+	return runes[inGroup%len(runes)]
+}
+
+// Slice returns runes [from, to) of expanded string, it panics when bounds are out of range [0, Len()].
+func (idx *Index) Slice(from, to int) string {
+	if from < 0 || to < from || to > idx.Len() {
+		panic(fmt.Sprintf("repeatgroup: slice bounds [%d:%d] out of range [0:%d]", from, to, idx.Len()))
+	}
+
+	var sb strings.Builder
+	for it := idx.Iter(from); it.pos < to; {
+		g := idx.groups[it.group]
+
+		// whole repetitions of group are copied at once
+		if it.inGroup%len(g.runes) == 0 && len(g.runes) <= to-it.pos {
+			reps := (to - it.pos) / len(g.runes)
+			if left := g.repeatCnt - it.inGroup/len(g.runes); reps > left {
+				reps = left
+			}
+			for i := 0; i < reps; i++ {
+				sb.Write(g.buffer)
+			}
+			it.skip(reps * len(g.runes))
+
+			continue
+		}
+
+		it.Next()
+		sb.WriteRune(it.Rune())
+	}
+
+	return sb.String()
+}
+
+// Iter returns iterator over runes of expanded string starting from rune with index from.
+func (idx *Index) Iter(from int) *Iterator {
+	if from < 0 || from > idx.Len() {
+		panic(fmt.Sprintf("repeatgroup: index %d out of range [0:%d]", from, idx.Len()))
+	}
+
+	it := &Iterator{idx: idx, pos: from}
+	if from < idx.Len() {
+		it.group, it.inGroup = idx.locate(from)
+	} else {
+		it.group = len(idx.groups)
+	}
+
+	return it
+}
+
+// Iterator walks over runes of expanded string.
+//
+//	for it := idx.Iter(0); it.Next(); {
+//		fmt.Println(it.Index(), it.Rune())
+//	}
+type Iterator struct {
+	idx     *Index
+	group   int // group of the next rune
+	inGroup int // index of the next rune inside expanded group
+	pos     int // index of the next rune
+	current rune
+}
+
+// Next advances iterator to the next rune, it returns false when runes are over.
+func (it *Iterator) Next() bool {
+	// runes after saturated length aren't indexed
+	if it.group >= len(it.idx.groups) || it.pos >= it.idx.Len() {
+		return false
+	}
+
+	runes := it.idx.groups[it.group].runes
+	it.current = runes[it.inGroup%len(runes)]
+	it.skip(1)
+
+	return true
+}
+
+// Rune returns current rune.
+func (it *Iterator) Rune() rune {
+	return it.current
+}
+
+// Index returns index of current rune in expanded string.
+func (it *Iterator) Index() int {
+	return it.pos - 1
+}
+
+// skip moves iterator n runes forward inside current group.
+func (it *Iterator) skip(n int) {
+	it.pos += n
+	it.inGroup += n
+
+	g := it.idx.groups[it.group]
+	if it.inGroup == groupSize(len(g.runes), g.repeatCnt) {
+		it.group++
+		it.inGroup = 0
+	}
+}
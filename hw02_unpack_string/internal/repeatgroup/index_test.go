@@ -0,0 +1,140 @@
+package repeatgroup
+
+import (
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIndex(t *testing.T) {
+	for _, tt := range [...]string{
+		``,
+		`a`,
+		`a4bc2d5e`,
+		`a0b0`,
+		"ы3か0\U0001F468\U0001F3FE\u200D\U0001F6802\\\\4",
+		"e\u0301\u03013x",
+		`q\45`,
+	} {
+		t.Run(tt, func(t *testing.T) {
+			gs, err := ParseString(tt)
+			require.NoError(t, err)
+			unpacked, err := gs.Unpack()
+			require.NoError(t, err)
+			expected := []rune(unpacked)
+
+			idx := gs.(GroupStorage).Index()
+			require.Equal(t, len(expected), idx.Len())
+
+			for i, r := range expected {
+				require.Equal(t, r, idx.At(i), "rune %d", i)
+			}
+			for from := 0; from <= len(expected); from++ {
+				for to := from; to <= len(expected); to++ {
+					require.Equal(t, string(expected[from:to]), idx.Slice(from, to), "slice [%d:%d]", from, to)
+				}
+			}
+
+			var runes []rune
+			for it := idx.Iter(0); it.Next(); {
+				require.Equal(t, len(runes), it.Index())
+				runes = append(runes, it.Rune())
+			}
+			require.Equal(t, string(expected), string(runes))
+		})
+	}
+}
+
+func TestIndexRandom(t *testing.T) {
+	rnd := rand.New(rand.NewSource(42)) //nolint:gosec
+	alphabet := []string{"a", "ы", "\U0001F468\U0001F3FD", "e\u0301", `\\`, `\5`}
+
+	for n := 0; n < 200; n++ {
+		var sb strings.Builder
+		for i := rnd.Intn(20); i > 0; i-- {
+			sb.WriteString(alphabet[rnd.Intn(len(alphabet))])
+			if rnd.Intn(2) == 0 {
+				sb.WriteByte(byte('0' + rnd.Intn(10)))
+			}
+		}
+
+		gs, err := ParseString(sb.String())
+		require.NoError(t, err)
+		unpacked, err := gs.Unpack()
+		require.NoError(t, err)
+		expected := []rune(unpacked)
+
+		idx := gs.(GroupStorage).Index()
+		require.Equal(t, len(expected), idx.Len())
+		for i := 0; i < 20 && len(expected) > 0; i++ {
+			from := rnd.Intn(len(expected) + 1)
+			to := from + rnd.Intn(len(expected)-from+1)
+			require.Equal(t, expected[from%len(expected)], idx.At(from%len(expected)), "input %q", sb.String())
+			require.Equal(t, string(expected[from:to]), idx.Slice(from, to), "input %q", sb.String())
+		}
+	}
+}
+
+func TestIndexLargeExpansion(t *testing.T) {
+	if strconv.IntSize == 32 {
+		t.Skip("expanded string is longer then math.MaxInt runes")
+	}
+
+	p, err := NewParser(ParserOptions{MultiDigitCount: true})
+	require.NoError(t, err)
+
+	// about 10 GB when expanded
+	gs, err := p.ParseString(`ab1000000000c2147483647d`)
+	require.NoError(t, err)
+
+	idx := gs.(GroupStorage).Index()
+	require.Equal(t, int64(1+1_000_000_000+math.MaxInt32+1), int64(idx.Len()))
+	require.Equal(t, 'a', idx.At(0))
+	require.Equal(t, 'b', idx.At(1_000_000_000))
+	require.Equal(t, 'c', idx.At(1_000_000_001))
+	require.Equal(t, 'd', idx.At(idx.Len()-1))
+	require.Equal(t, "bbcc", idx.Slice(999_999_999, 1_000_000_003))
+
+	var tail []rune
+	for it := idx.Iter(idx.Len() - 3); it.Next(); {
+		tail = append(tail, it.Rune())
+	}
+	require.Equal(t, "ccd", string(tail))
+}
+
+func TestIndexOutOfRange(t *testing.T) {
+	gs, err := ParseString(`a3`)
+	require.NoError(t, err)
+	idx := gs.(GroupStorage).Index()
+
+	require.Panics(t, func() { idx.At(3) })
+	require.Panics(t, func() { idx.At(-1) })
+	require.Panics(t, func() { idx.Slice(2, 1) })
+	require.Panics(t, func() { idx.Slice(0, 4) })
+	require.Panics(t, func() { idx.Iter(4) })
+	require.False(t, idx.Iter(3).Next())
+}
+
+func TestIndexSaturation(t *testing.T) {
+	gs := GroupStorage{[]repeatGroup{
+		{[]byte("ab"), math.MaxInt/2 + 1},
+		{[]byte("c"), 5},
+	}}
+
+	idx := gs.Index()
+	require.Equal(t, math.MaxInt, idx.Len())
+	require.Equal(t, 'a', idx.At(0))
+	require.Equal(t, 'b', idx.At(math.MaxInt-2))
+	require.Equal(t, 'a', idx.At(math.MaxInt-1))
+	require.Equal(t, "ba", idx.Slice(math.MaxInt-2, math.MaxInt))
+
+	var tail []rune
+	for it := idx.Iter(math.MaxInt - 1); it.Next(); {
+		tail = append(tail, it.Rune())
+	}
+	require.Equal(t, []rune{'a'}, tail)
+}
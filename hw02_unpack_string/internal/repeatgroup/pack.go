@@ -0,0 +1,113 @@
+package repeatgroup
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/rivo/uniseg"
+
+	"github.com/PrideSt/otus-golang/hw02_unpack_string/internal/reader"
+)
+
+// maxRepeatCnt is the biggest count single digit can encode.
+const maxRepeatCnt = 9
+
+var (
+	// ErrInvalidUTF8 happens when input for Pack isn't valid utf8 string.
+	ErrInvalidUTF8 = errors.New("invalid utf8")
+	// ErrGroupTooLong matches any *GroupTooLongError.
+	ErrGroupTooLong = errors.New("group is too long to pack")
+)
+
+// Pack encodes input into the shortest string which ParseString decodes back to input.
+// It fails with *GroupTooLongError when input has group longer then MaxGroupSize bytes,
+// e.g. result of Unpack repeating cluster of combining marks.
+func Pack(input string) (string, error) {
+	if !utf8.ValidString(input) {
+		return "", fmt.Errorf("unable to pack %q, %w", input, ErrInvalidUTF8)
+	}
+
+	var result strings.Builder
+	units := splitUnits(input)
+
+	offset := 0
+	for _, unit := range units {
+		// clusterReader cuts long cluster, so every part of it is longer then limit too
+		if len(unit) > MaxGroupSize {
+			return "", &GroupTooLongError{Offset: offset, Size: len(unit)}
+		}
+		offset += len(unit)
+	}
+
+	for i := 0; i < len(units); {
+		// count equal units in a row
+		cnt := 1
+		for i+cnt < len(units) && units[i+cnt] == units[i] {
+			cnt++
+		}
+
+		encoded := encodeUnit(units[i])
+		if i+cnt < len(units) && gluesToDigit(units[i+cnt]) {
+			// the next unit would extend count digit, so the last unit of run is written without count
+			writeRun(&result, encoded, cnt-1)
+			result.WriteString(encoded)
+		} else {
+			writeRun(&result, encoded, cnt)
+		}
+		i += cnt
+	}
+
+	// input can't end with joiner, so trailing joiner is followed by explicit count
+	if r, _ := utf8.DecodeLastRuneInString(result.String()); isJoiner(r) {
+		result.WriteByte('1')
+	}
+
+	return result.String(), nil
+}
+
+// splitUnits splits decoded text into groups the same way parse joins clusters into one buffer.
+func splitUnits(input string) []string {
+	var units []string
+
+	r := reader.Make([]byte(input), 0)
+	clusters := newClusterReader(&r)
+	for !clusters.IsEOF() {
+		cluster, _ := clusters.GetNext()
+		if len(units) > 0 && startsWithSymbolModifier(cluster) {
+			units[len(units)-1] += string(cluster)
+
+			continue
+		}
+		units = append(units, string(cluster))
+	}
+
+	return units
+}
+
+// encodeUnit escapes group when its first cluster is treated by parse as control symbol.
+func encodeUnit(unit string) string {
+	first, _, _, _ := uniseg.FirstGraphemeClusterInString(unit, -1)
+	if r, ok := asSingleRune([]byte(first)); ok && (isASCIIDigit(r) || isEscapeSymbol(r)) {
+		return `\` + unit
+	}
+
+	return unit
+}
+
+// writeRun writes encoded group repeated cnt times, splitting count into single digits.
+func writeRun(sb *strings.Builder, encoded string, cnt int) {
+	for cnt > 0 {
+		chunk := cnt
+		if chunk > maxRepeatCnt {
+			chunk = maxRepeatCnt
+		}
+		cnt -= chunk
+
+		sb.WriteString(encoded)
+		if chunk > 1 {
+			sb.WriteByte(byte('0' + chunk))
+		}
+	}
+}
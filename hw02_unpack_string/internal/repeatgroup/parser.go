@@ -0,0 +1,243 @@
+package repeatgroup
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"unicode/utf8"
+
+	"github.com/PrideSt/otus-golang/hw02_unpack_string/internal/reader"
+)
+
+const defaultEscape = '\\'
+
+// MaxGroupSize limits size of group in bytes, so parse buffer doesn't grow on endless
+// runs of combining marks or modifiers. Real grapheme clusters are much shorter.
+// Repeated group of marks expands into one longer cluster, Pack fails with *GroupTooLongError on it.
+const MaxGroupSize = 1024
+
+var (
+	// ErrInvalidOptions happens when ParserOptions describe ambiguous grammar.
+	ErrInvalidOptions = errors.New("invalid parser options")
+	// ErrSizeLimit happens when expanded string is longer then limit, it matches any *SizeLimitError.
+	ErrSizeLimit = errors.New("expanded size limit exceeded")
+)
+
+// ZeroCount defines meaning of zero repeat count.
+type ZeroCount int
+
+const (
+	// ZeroCountDrop removes group repeated zero times, "aa0b" => "ab".
+	ZeroCountDrop ZeroCount = iota
+	// ZeroCountError treats zero repeat count as invalid format.
+	ZeroCountError
+)
+
+// ParserOptions configures grammar of encoded strings, zero value is the default grammar.
+type ParserOptions struct {
+	// MultiDigitCount allows counts of several digits, "a12" => "aaaaaaaaaaaa".
+	MultiDigitCount bool
+	// Escape is a symbol which makes the next one literal, '\' when zero.
+	Escape rune
+	// ZeroCount defines meaning of zero repeat count.
+	ZeroCount ZeroCount
+	// MaxSize limits length of expanded string in bytes, zero means unlimited.
+	MaxSize int
+}
+
+// parser implements Parser with configurable grammar.
+type parser struct {
+	opts ParserOptions
+}
+
+var defaultParser = parser{ParserOptions{Escape: defaultEscape}}
+
+// NewParser creates Parser with given grammar.
+func NewParser(opts ParserOptions) (Parser, error) {
+	if opts.Escape == 0 {
+		opts.Escape = defaultEscape
+	}
+
+	switch {
+	case !utf8.ValidRune(opts.Escape):
+		return nil, fmt.Errorf("%w: escape %U isn't valid rune", ErrInvalidOptions, opts.Escape)
+	case isASCIIDigit(opts.Escape) || isJoiner(opts.Escape) || isSymbolModifier(opts.Escape) || isMarkNonspacing(opts.Escape):
+		return nil, fmt.Errorf("%w: escape %q has special meaning", ErrInvalidOptions, opts.Escape)
+	case opts.ZeroCount != ZeroCountDrop && opts.ZeroCount != ZeroCountError:
+		return nil, fmt.Errorf("%w: unknown zero count mode %d", ErrInvalidOptions, opts.ZeroCount)
+	case opts.MaxSize < 0:
+		return nil, fmt.Errorf("%w: negative max size %d", ErrInvalidOptions, opts.MaxSize)
+	}
+
+	return parser{opts}, nil
+}
+
+// ParseString converts input string to internal state.
+func (p parser) ParseString(input string) (Unpacker, error) {
+	var gs GroupStorage
+	reader := reader.Make([]byte(input), 0)
+
+	err := p.parse(&reader, func(b []byte, cnt int) error {
+		gs.add(b, cnt)
+
+		return nil
+	})
+	if err != nil {
+		var pe *ParseError
+		if errors.As(err, &pe) {
+			pe.Input = input
+		}
+
+		return GroupStorage{}, err
+	}
+
+	return gs, nil
+}
+
+// UnpackReader parses input from r group by group and writes expanded groups to w,
+// memory usage doesn't depend on input size. Part of output may be written before error happens.
+func (p parser) UnpackReader(r io.Reader, w io.Writer) error {
+	stream := reader.NewStream(r)
+	bw := bufio.NewWriter(w)
+
+	err := p.parse(stream, func(b []byte, cnt int) error {
+		for i := 0; i < cnt; i++ {
+			if _, err := bw.Write(b); err != nil {
+				return fmt.Errorf("unable to write chunk %q, %w", string(b), err)
+			}
+		}
+
+		return nil
+	})
+	// read error breaks parsing like EOF, so it must be checked first
+	if readErr := stream.Err(); readErr != nil {
+		return fmt.Errorf("unable to read input, %w", readErr)
+	}
+	if err != nil {
+		return err
+	}
+
+	return bw.Flush()
+}
+
+func newParseError(kind ErrorKind, pos position) *ParseError {
+	return &ParseError{Kind: kind, Offset: pos.offset, Index: pos.index, Size: pos.size}
+}
+
+// parse reads extended grapheme clusters one by one and passes every complete group to emit,
+// given buffer is reused after emit returns.
+func (p parser) parse(reader RuneReader, emit func(b []byte, cnt int) error) error {
+	var buffer bytes.Buffer
+	var size int
+
+	// flush emits group from buffer and resets them, does nothing when buffer is empty
+	flush := func(cnt int) error {
+		if buffer.Len() == 0 {
+			return nil
+		}
+		defer buffer.Reset()
+
+		if p.opts.MaxSize > 0 {
+			size = addSize(size, groupSize(buffer.Len(), cnt))
+			if size > p.opts.MaxSize {
+				return &SizeLimitError{Size: size, Limit: p.opts.MaxSize}
+			}
+		}
+
+		return emit(buffer.Bytes(), cnt)
+	}
+
+	// count accumulates multi digit repeat count until the first non digit cluster
+	var countPos position
+	count, inCount := 0, false
+	flushCount := func() error {
+		if !inCount {
+			return nil
+		}
+		inCount = false
+
+		if count == 0 && p.opts.ZeroCount == ZeroCountError {
+			return newParseError(KindZeroCount, countPos)
+		}
+
+		return flush(count)
+	}
+
+	// the last cluster read, input can't end with joiner
+	var last []byte
+	var lastPos position
+
+	clusters := newClusterReader(reader)
+	for !clusters.IsEOF() {
+		cluster, pos := clusters.GetNext()
+		last, lastPos = cluster, pos
+
+		if r, ok := asSingleRune(cluster); ok && isASCIIDigit(r) {
+			digit := int(r - '0')
+
+			switch {
+			case inCount && count > (math.MaxInt32-digit)/10:
+				countPos.size = pos.end() - countPos.offset
+
+				return newParseError(KindCountOverflow, countPos)
+			case inCount:
+				count = count*10 + digit
+				countPos.size = pos.end() - countPos.offset
+			case buffer.Len() == 0:
+				return newParseError(KindLeadingDigit, pos)
+			default:
+				count, inCount, countPos = digit, true, pos
+			}
+
+			if !p.opts.MultiDigitCount {
+				if err := flushCount(); err != nil {
+					return err
+				}
+			}
+
+			continue
+		}
+
+		if err := flushCount(); err != nil {
+			return err
+		}
+
+		var err error
+		if r, ok := asSingleRune(cluster); ok && r == p.opts.Escape {
+			// if escape is last cluster do nothing, add them to buffer like any another
+			// otherwise read next cluster
+			err = flush(1)
+			if !clusters.IsEOF() {
+				// overwrite variables in outer scope
+				cluster, pos = clusters.GetNext()
+				last, lastPos = cluster, pos
+			}
+		} else if buffer.Len() == 0 || !startsWithSymbolModifier(cluster) {
+			err = flush(1)
+		}
+		if err != nil {
+			return err
+		}
+		if buffer.Len()+len(cluster) > MaxGroupSize {
+			return newParseError(KindGroupTooLong, pos)
+		}
+		buffer.Write(cluster)
+	}
+
+	if r, size := utf8.DecodeLastRune(last); isJoiner(r) {
+		return newParseError(KindTrailingJoiner, position{
+			offset: lastPos.end() - size,
+			index:  lastPos.index + utf8.RuneCount(last) - 1,
+			size:   size,
+		})
+	}
+
+	if err := flushCount(); err != nil {
+		return err
+	}
+
+	return flush(1)
+}
@@ -0,0 +1,173 @@
+package repeatgroup
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewParser(t *testing.T) {
+	for _, tt := range [...]struct {
+		name string
+		opts ParserOptions
+		err  error
+	}{
+		{
+			name: `Default options`,
+			opts: ParserOptions{},
+		},
+		{
+			name: `Custom escape`,
+			opts: ParserOptions{Escape: '%'},
+		},
+		{
+			name: `Digit escape`,
+			opts: ParserOptions{Escape: '5'},
+			err:  ErrInvalidOptions,
+		},
+		{
+			name: `Joiner escape`,
+			opts: ParserOptions{Escape: '\u200D'},
+			err:  ErrInvalidOptions,
+		},
+		{
+			name: `Combining mark escape`,
+			opts: ParserOptions{Escape: '\u0301'},
+			err:  ErrInvalidOptions,
+		},
+		{
+			name: `Unknown zero count mode`,
+			opts: ParserOptions{ZeroCount: 42},
+			err:  ErrInvalidOptions,
+		},
+		{
+			name: `Negative max size`,
+			opts: ParserOptions{MaxSize: -1},
+			err:  ErrInvalidOptions,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewParser(tt.opts)
+			require.True(t, errors.Is(err, tt.err), "unexpected error %v", err)
+		})
+	}
+}
+
+func TestParserOptions(t *testing.T) {
+	for _, tt := range [...]struct {
+		name     string
+		opts     ParserOptions
+		input    string
+		expected string
+		err      error
+	}{
+		{
+			name:     `Default grammar`,
+			input:    `a4b\\2c0`,
+			expected: `aaaab\\`,
+		},
+		{
+			name:     `Multi digit count`,
+			opts:     ParserOptions{MultiDigitCount: true},
+			input:    `a12b3`,
+			expected: strings.Repeat("a", 12) + "bbb",
+		},
+		{
+			name:     `Multi digit count with leading zero`,
+			opts:     ParserOptions{MultiDigitCount: true},
+			input:    `a02`,
+			expected: `aa`,
+		},
+		{
+			name:     `Multi digit zero count`,
+			opts:     ParserOptions{MultiDigitCount: true},
+			input:    `a00b`,
+			expected: `b`,
+		},
+		{
+			name:  `Multi digit count overflow`,
+			opts:  ParserOptions{MultiDigitCount: true},
+			input: `a99999999999`,
+			err:   &ParseError{Kind: KindCountOverflow, Offset: 1, Index: 1, Size: 10, Input: `a99999999999`},
+		},
+		{
+			name:  `Several digits without multi digit count`,
+			input: `a12`,
+			err:   &ParseError{Kind: KindLeadingDigit, Offset: 2, Index: 2, Size: 1, Input: `a12`},
+		},
+		{
+			name:     `Custom escape`,
+			opts:     ParserOptions{Escape: '%'},
+			input:    `a%3\2%%2`,
+			expected: `a3\\%%`,
+		},
+		{
+			name:  `Zero count error`,
+			opts:  ParserOptions{ZeroCount: ZeroCountError},
+			input: `ab0c`,
+			err:   &ParseError{Kind: KindZeroCount, Offset: 2, Index: 2, Size: 1, Input: `ab0c`},
+		},
+		{
+			name:  `Multi digit zero count error`,
+			opts:  ParserOptions{MultiDigitCount: true, ZeroCount: ZeroCountError},
+			input: `ab00c`,
+			err:   &ParseError{Kind: KindZeroCount, Offset: 2, Index: 2, Size: 2, Input: `ab00c`},
+		},
+		{
+			name:     `Multi digit count ends with zero`,
+			opts:     ParserOptions{MultiDigitCount: true, ZeroCount: ZeroCountError},
+			input:    `a10`,
+			expected: strings.Repeat("a", 10),
+		},
+		{
+			name:     `Size fits limit`,
+			opts:     ParserOptions{MaxSize: 5},
+			input:    `a3ы`,
+			expected: `aaaы`,
+		},
+		{
+			name:  `Size limit exceeded`,
+			opts:  ParserOptions{MaxSize: 4},
+			input: `a3ы`,
+			err:   ErrSizeLimit,
+		},
+		{
+			name:  `Size limit exceeded by multi digit count`,
+			opts:  ParserOptions{MaxSize: 1000, MultiDigitCount: true},
+			input: `ab999999999`,
+			err:   ErrSizeLimit,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewParser(tt.opts)
+			require.NoError(t, err)
+
+			gs, err := p.ParseString(tt.input)
+			switch {
+			case errors.Is(tt.err, ErrSizeLimit):
+				require.True(t, errors.Is(err, ErrSizeLimit), "unexpected error %v", err)
+			case tt.err != nil:
+				require.Equal(t, tt.err, err)
+			default:
+				require.NoError(t, err)
+
+				result, err := gs.Unpack()
+				require.NoError(t, err)
+				require.Equal(t, tt.expected, result)
+			}
+
+			// stream parser follows the same grammar
+			var out bytes.Buffer
+			err = p.UnpackReader(strings.NewReader(tt.input), &out)
+			if tt.err != nil {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, tt.expected, out.String())
+			}
+		})
+	}
+}
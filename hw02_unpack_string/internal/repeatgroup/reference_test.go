@@ -0,0 +1,153 @@
+package repeatgroup
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// referenceError describes malformed input found by referenceUnpack,
+// offset points to the start of the wrong cluster.
+type referenceError struct {
+	kind   ErrorKind
+	offset int
+}
+
+func (e *referenceError) Error() string {
+	return fmt.Sprintf("%v at offset %d", e.kind, e.offset)
+}
+
+// runeClass is grapheme break property of rune in terms of naive segmentation.
+type runeClass int
+
+const (
+	classOther runeClass = iota
+	classControl
+	classCR
+	classLF
+	classExtend
+)
+
+// naiveClass returns class of rune for the small alphabet where segmentation is simple:
+// ASCII, Latin, IPA, spacing modifiers, Greek, Cyrillic, combining marks, joiners and U+FFFD.
+// Segmentation of other runes (Hangul, emoji, flags, prepend, spacing marks...) takes
+// UAX #29 tables, it is checked by TestClusterReaderConformance, so ok is false for them.
+func naiveClass(r rune) (class runeClass, ok bool) {
+	switch {
+	case r == '\r':
+		return classCR, true
+	case r == '\n':
+		return classLF, true
+	case r < 0x20 || 0x7F <= r && r < 0xA0 || r == 0xAD:
+		return classControl, true
+	case r == 0xA9 || r == 0xAE:
+		// © and ® are extended pictographic, joiner glues them to the next one
+		return classOther, false
+	case 0x300 <= r && r <= 0x36F || 0x483 <= r && r <= 0x489 || r == 0x200C || r == 0x200D:
+		return classExtend, true
+	case r < 0x500 || r == unicode.ReplacementChar:
+		return classOther, true
+	}
+
+	return classOther, false
+}
+
+// referenceCluster is a grapheme cluster with offset in original input.
+type referenceCluster struct {
+	text    string
+	offset  int
+	control bool
+}
+
+// naiveSplit splits input into grapheme clusters by the rules of UAX #29 which matter for naiveClass alphabet:
+// CR LF is a single cluster, controls are alone, extending runes are glued to the previous cluster.
+// Every invalid byte becomes utf8.RuneError. It returns false when input has rune outside the alphabet.
+func naiveSplit(input string) ([]referenceCluster, bool) {
+	var clusters []referenceCluster
+	prev := classControl
+	for offset, r := range input {
+		class, ok := naiveClass(r)
+		if !ok {
+			return nil, false
+		}
+
+		last := len(clusters) - 1
+		switch {
+		case class == classLF && prev == classCR,
+			class == classExtend && last >= 0 && !clusters[last].control:
+			clusters[last].text += string(r)
+		default:
+			clusters = append(clusters, referenceCluster{
+				text:    string(r),
+				offset:  offset,
+				control: class == classControl || class == classCR || class == classLF,
+			})
+		}
+		prev = class
+	}
+
+	return clusters, true
+}
+
+// referenceUnpack is slow but straightforward decoder of the default grammar, it is an oracle for fuzz tests.
+// It splits the whole input into clusters first and then interprets them:
+// single ASCII digit repeats previous group, '\' makes the next cluster literal,
+// cluster starting with Sk symbol is glued to previous group, input can't end with joiner.
+// It returns false when input can't be split by naiveSplit.
+func referenceUnpack(input string) (string, bool, error) {
+	clusters, ok := naiveSplit(input)
+	if !ok {
+		return "", false, nil
+	}
+
+	var out strings.Builder
+	var group string
+	hasGroup := false
+
+	flush := func() {
+		if hasGroup {
+			out.WriteString(group)
+		}
+		hasGroup = false
+	}
+
+	for i := 0; i < len(clusters); i++ {
+		c := clusters[i].text
+
+		switch {
+		case len(c) == 1 && '0' <= c[0] && c[0] <= '9':
+			if !hasGroup {
+				return "", true, &referenceError{KindLeadingDigit, clusters[i].offset}
+			}
+			out.WriteString(strings.Repeat(group, int(c[0]-'0')))
+			hasGroup = false
+
+			continue
+		case c == `\` && i+1 < len(clusters):
+			flush()
+			i++
+			c = clusters[i].text
+		case hasGroup && unicode.In([]rune(c)[0], unicode.Sk):
+			if len(group)+len(c) > MaxGroupSize {
+				return "", true, &referenceError{KindGroupTooLong, clusters[i].offset}
+			}
+			group += c
+
+			continue
+		default:
+			flush()
+		}
+
+		if len(c) > MaxGroupSize {
+			return "", true, &referenceError{KindGroupTooLong, clusters[i].offset}
+		}
+		group, hasGroup = c, true
+	}
+
+	if last := len(input) - len("\u200D"); strings.HasSuffix(input, "\u200C") || strings.HasSuffix(input, "\u200D") {
+		return "", true, &referenceError{KindTrailingJoiner, last}
+	}
+	flush()
+
+	return out.String(), true, nil
+}
@@ -1,16 +1,15 @@
 package repeatgroup
 
 import (
-	"bytes"
-	"fmt"
+	"io"
 	"unicode"
-
-	"github.com/PrideSt/otus-golang/hw02_unpack_string/internal/reader"
 )
 
 // Parser can create Unpacker from string, create internal view from encoded string.
 type Parser interface {
 	ParseString(str string) (Unpacker, error)
+	// UnpackReader expands input from r group by group and writes result to w.
+	UnpackReader(r io.Reader, w io.Writer) error
 }
 
 // RepeatGroup basic struct of internal representation.
@@ -25,7 +24,7 @@ func isASCIIDigit(r rune) bool {
 }
 
 func isEscapeSymbol(r rune) bool {
-	return r == '\\'
+	return r == defaultEscape
 }
 
 // @see https://www.fileformat.info/info/unicode/category/Sk/list.htm
@@ -46,51 +45,18 @@ func isJoiner(r rune) bool {
 	return unicode.In(r, unicode.Join_Control)
 }
 
-// ParseString converts input string to internal state.
-func ParseString(input string) (Unpacker, error) {
-	var gs GroupStorage
-	var buffer bytes.Buffer
-	reader := reader.Make([]byte(input), 0)
-
-	for !reader.IsEOF() {
-		r, offset := reader.GetNext()
-
-		switch {
-		case isASCIIDigit(r):
-			if buffer.Len() == 0 {
-				return GroupStorage{}, fmt.Errorf("invalid format %s, offset: %d, digit can't be the first symbol", input, offset)
-			}
-
-			gs.flushBuffer(&buffer, int(r-'0'))
-
-			continue
-		case isEscapeSymbol(r):
-			// if escape is last symbol do nothing, add them to buffer like any another
-			// otherwise read next symbol
-			gs.flushBuffer(&buffer, 1)
-			if !reader.IsEOF() {
-				// overwrite variables in outer scope
-				r, _ = reader.GetNext()
-			}
-		case isJoiner(r):
-			// write joiner
-			buffer.WriteRune(r)
-
-			// write next symbol
-			if !reader.IsEOF() {
-				// overwrite variables in outer scope
-				r, _ = reader.GetNext()
-			} else {
-				return GroupStorage{}, fmt.Errorf("invalid format %s, offset: %d, input string can't ends with joiner", input, offset)
-			}
-		case isSymbolModifier(r) || isMarkNonspacing(r):
-		default:
-			gs.flushBuffer(&buffer, 1)
-		}
-		buffer.WriteRune(r)
-	}
+// RuneReader is a source of runes for parser.
+type RuneReader interface {
+	GetNext() (rune, int)
+	IsEOF() bool
+}
 
-	gs.flushBuffer(&buffer, 1)
+// ParseString converts input string to internal state using default grammar.
+func ParseString(input string) (Unpacker, error) {
+	return defaultParser.ParseString(input)
+}
 
-	return gs, nil
+// UnpackReader parses input from r group by group and writes expanded groups to w using default grammar.
+func UnpackReader(r io.Reader, w io.Writer) error {
+	return defaultParser.UnpackReader(r, w)
 }
@@ -1,7 +1,6 @@
 package repeatgroup
 
 import (
-	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -93,13 +92,13 @@ func TestParseString(t *testing.T) {
 			name:     `Repeat times more then 9`,
 			input:    `q12`,
 			expected: GroupStorage{},
-			err:      fmt.Errorf("invalid format q12, offset: 3, digit can't be the first symbol"),
+			err:      &ParseError{Kind: KindLeadingDigit, Offset: 2, Index: 2, Size: 1, Input: `q12`},
 		},
 		{
 			name:     `Starts from digit`,
 			input:    `1a2`,
 			expected: GroupStorage{},
-			err:      fmt.Errorf("invalid format 1a2, offset: 1, digit can't be the first symbol"),
+			err:      &ParseError{Kind: KindLeadingDigit, Offset: 0, Index: 0, Size: 1, Input: `1a2`},
 		},
 		// tests with unicode
 		{
@@ -174,6 +173,69 @@ func TestParseString(t *testing.T) {
 				{[]byte{0xF0, 0x9D, 0x9F, 0xB9}, 2},
 			}},
 		},
+		// extended grapheme clusters
+		{
+			name:  `Emoji with skin tone`,
+			input: "\U0001F44B\U0001F3FF3",
+			expected: GroupStorage{[]repeatGroup{
+				{[]byte("\U0001F44B\U0001F3FF"), 3},
+			}},
+		},
+		{
+			name:  `Flags are pairs of regional indicators`,
+			input: "\U0001F1F7\U0001F1FA2\U0001F1FA\U0001F1F8\U0001F1E9",
+			expected: GroupStorage{[]repeatGroup{
+				{[]byte("\U0001F1F7\U0001F1FA"), 2},
+				{[]byte("\U0001F1FA\U0001F1F8"), 1},
+				{[]byte("\U0001F1E9"), 1},
+			}},
+		},
+		{
+			name:  `Variation selector`,
+			input: "\u26052\u2605\uFE0F3",
+			expected: GroupStorage{[]repeatGroup{
+				{[]byte("\u2605"), 2},
+				{[]byte("\u2605\uFE0F"), 3},
+			}},
+		},
+		{
+			name:  `Hangul jamo`,
+			input: "\u1100\u1161\u11A82\u1100\u1100\u1161",
+			expected: GroupStorage{[]repeatGroup{
+				{[]byte("\u1100\u1161\u11A8"), 2},
+				{[]byte("\u1100\u1100\u1161"), 1},
+			}},
+		},
+		{
+			name:  `CR LF`,
+			input: "a\r\n3",
+			expected: GroupStorage{[]repeatGroup{
+				{[]byte("a"), 1},
+				{[]byte("\r\n"), 3},
+			}},
+		},
+		{
+			name:  `Digit after joiner is count`,
+			input: "a\u200D2",
+			expected: GroupStorage{[]repeatGroup{
+				{[]byte("a\u200D"), 2},
+			}},
+		},
+		{
+			name:  `Count after trailing joiner`,
+			input: "a\u200D1",
+			expected: GroupStorage{[]repeatGroup{
+				{[]byte("a\u200D"), 1},
+			}},
+		},
+		{
+			name:  `Combining mark glues to digit`,
+			input: "a2\u0301",
+			expected: GroupStorage{[]repeatGroup{
+				{[]byte("a"), 1},
+				{[]byte("2\u0301"), 1},
+			}},
+		},
 	} {
 		t.Run(tt.name, func(t *testing.T) {
 			result, err := ParseString(tt.input)
@@ -0,0 +1,17 @@
+package hw02_unpack_string //nolint:golint,stylecheck
+
+import (
+	"fmt"
+
+	"github.com/PrideSt/otus-golang/hw02_unpack_string/internal/repeatgroup"
+)
+
+// Pack encode input string, it is inverse function of Unpack.
+func Pack(input string) (string, error) {
+	packed, err := repeatgroup.Pack(input)
+	if err != nil {
+		return "", fmt.Errorf("unable to pack string, %w", err)
+	}
+
+	return packed, nil
+}
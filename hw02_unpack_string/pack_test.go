@@ -0,0 +1,196 @@
+package hw02_unpack_string //nolint:golint,stylecheck
+
+import (
+	"errors"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/quick"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/PrideSt/otus-golang/hw02_unpack_string/internal/repeatgroup"
+)
+
+func TestPack(t *testing.T) {
+	for _, tt := range [...]struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     `Empty string`,
+			input:    ``,
+			expected: ``,
+		},
+		{
+			name:     `Simple repeat`,
+			input:    `aaaabccddddde`,
+			expected: `a4bc2d5e`,
+		},
+		{
+			name:     `Run longer then 9`,
+			input:    strings.Repeat("a", 22),
+			expected: `a9a9a4`,
+		},
+		{
+			name:     `Run of 10`,
+			input:    strings.Repeat("a", 10),
+			expected: `a9a`,
+		},
+		{
+			name:     `Digits escaped`,
+			input:    `qwe45`,
+			expected: `qwe\4\5`,
+		},
+		{
+			name:     `Repeated digit`,
+			input:    `qwe44444`,
+			expected: `qwe\45`,
+		},
+		{
+			name:     `Backslashes`,
+			input:    `qwe\\\\\a`,
+			expected: `qwe\\5a`,
+		},
+		{
+			name:     `Combining marks`,
+			input:    "ae\u0301\u0301e\u0301\u0301",
+			expected: "ae\u0301\u03012",
+		},
+		{
+			name:     `Emoji with joiner`,
+			input:    `b👨🏾‍🚀👨🏾‍🚀`,
+			expected: `b👨🏾‍🚀2`,
+		},
+		{
+			name:     `Digit after joiner`,
+			input:    "a\u200D1a\u200D1",
+			expected: "a\u200D\\1a\u200D\\1",
+		},
+		{
+			name:     `Trailing joiner`,
+			input:    "a\u200D",
+			expected: "a\u200D1",
+		},
+		{
+			name:     `Flags`,
+			input:    "\U0001F1F7\U0001F1FA\U0001F1F7\U0001F1FA\U0001F1F7\U0001F1FA",
+			expected: "\U0001F1F7\U0001F1FA3",
+		},
+		{
+			name:     `Combining mark after control`,
+			input:    "\t\t\t\u0301",
+			expected: "\t2\t\u0301",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Pack(tt.input)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, result)
+
+			unpacked, err := Unpack(result)
+			require.NoError(t, err)
+			require.Equal(t, tt.input, unpacked)
+		})
+	}
+
+	t.Run("invalid utf8", func(t *testing.T) {
+		_, err := Pack("a\xffb")
+		require.True(t, errors.Is(err, repeatgroup.ErrInvalidUTF8))
+		require.Equal(t, 1, strings.Count(err.Error(), `"a\xffb"`), "input is quoted once: %v", err)
+	})
+
+	t.Run("group too long", func(t *testing.T) {
+		for _, tt := range [...]struct {
+			input  string
+			offset int
+		}{
+			{"b" + strings.Repeat("\u0301", 800), 0},
+			{"ab" + strings.Repeat("\u02EF", 600), 1},
+		} {
+			_, err := Pack(tt.input)
+
+			var gle *GroupTooLongError
+			require.True(t, errors.As(err, &gle), "unexpected error %v", err)
+			require.True(t, errors.Is(err, ErrGroupTooLong))
+			require.Equal(t, tt.offset, gle.Offset)
+			require.Greater(t, gle.Size, repeatgroup.MaxGroupSize)
+		}
+
+		// result of Unpack can't be packed back when repeated group is glued into one long cluster
+		unpacked, err := Unpack(strings.Repeat("\u0301", 400) + "2")
+		require.NoError(t, err)
+		_, err = Pack(unpacked)
+		require.True(t, errors.Is(err, ErrGroupTooLong), "unexpected error %v", err)
+	})
+}
+
+// packAlphabet contains runes with special meaning for parser and some regular ones.
+var packAlphabet = []rune{
+	'a', 'b', 'ы', '👨', '🏽', '🚀', '𝟹', '\t', '\x00',
+	'0', '1', '9', '\\',
+	'\u200D', '\u200C', // joiners
+	'\U0001F1F7', '\U0001F1FA', '\uFE0F', // regional indicators, variation selector
+	'\u1100', '\u1161', '\u11A8', '\uAC00', // hangul jamo L, V, T and syllable LV
+	'\u0600', '\u0903', '\r', '\n', // prepend, spacing mark, CR LF
+	'\u0301', '\u02EF', // combining mark, modifier letter
+}
+
+// packInput is a random string generated from packAlphabet with long runs.
+type packInput string
+
+// Generate implements quick.Generator.
+func (packInput) Generate(rnd *rand.Rand, size int) reflect.Value {
+	var sb strings.Builder
+	for i := rnd.Intn(size + 1); i > 0; i-- {
+		r := packAlphabet[rnd.Intn(len(packAlphabet))]
+		for n := 1 + rnd.Intn(12); n > 0; n-- {
+			sb.WriteRune(r)
+		}
+	}
+
+	return reflect.ValueOf(packInput(sb.String()))
+}
+
+func TestPackProperties(t *testing.T) {
+	t.Run("unpack reverts pack", func(t *testing.T) {
+		err := quick.Check(func(s packInput) bool {
+			packed, err := Pack(string(s))
+			if err != nil {
+				return false
+			}
+			unpacked, err := Unpack(packed)
+
+			return err == nil && unpacked == string(s)
+		}, &quick.Config{MaxCount: 5000})
+		require.NoError(t, err)
+	})
+
+	t.Run("arbitrary strings", func(t *testing.T) {
+		err := quick.Check(func(s string) bool {
+			packed, err := Pack(s)
+			if err != nil {
+				return false
+			}
+			unpacked, err := Unpack(packed)
+
+			return err == nil && unpacked == s
+		}, &quick.Config{MaxCount: 5000})
+		require.NoError(t, err)
+	})
+
+	t.Run("packed size is bounded", func(t *testing.T) {
+		// every rune is escaped at most once
+		err := quick.Check(func(s packInput) bool {
+			packed, err := Pack(string(s))
+			if err != nil {
+				return false
+			}
+
+			return len(packed) <= 2*len(s)
+		}, &quick.Config{MaxCount: 1000})
+		require.NoError(t, err)
+	})
+}
@@ -2,16 +2,63 @@ package hw02_unpack_string //nolint:golint,stylecheck
 
 import (
 	"fmt"
+	"io"
 
 	"github.com/PrideSt/otus-golang/hw02_unpack_string/internal/repeatgroup"
 )
 
+// ParseError describes malformed input, use errors.As to get it from errors of Unpack and UnpackReader.
+type ParseError = repeatgroup.ParseError
+
+// Kinds of malformed input.
+const (
+	KindLeadingDigit   = repeatgroup.KindLeadingDigit
+	KindZeroCount      = repeatgroup.KindZeroCount
+	KindCountOverflow  = repeatgroup.KindCountOverflow
+	KindGroupTooLong   = repeatgroup.KindGroupTooLong
+	KindTrailingJoiner = repeatgroup.KindTrailingJoiner
+)
+
+// SizeLimitError happens when expanded string is longer then limit, errors.Is(err, ErrSizeLimit) is true for it.
+type SizeLimitError = repeatgroup.SizeLimitError
+
+// ErrSizeLimit matches any *SizeLimitError.
+var ErrSizeLimit = repeatgroup.ErrSizeLimit
+
+// GroupTooLongError happens when input of Pack has group which Unpack can't decode,
+// errors.Is(err, ErrGroupTooLong) is true for it.
+type GroupTooLongError = repeatgroup.GroupTooLongError
+
+// ErrGroupTooLong matches any *GroupTooLongError.
+var ErrGroupTooLong = repeatgroup.ErrGroupTooLong
+
 // Unpack decode input string.
 func Unpack(input string) (string, error) {
 	gs, err := repeatgroup.ParseString(input)
 	if err != nil {
-		return "", fmt.Errorf("unable to parse string %s, %w", input, err)
+		return "", fmt.Errorf("unable to parse string, %w", err)
 	}
 
 	return gs.Unpack()
 }
+
+// UnpackReader decode input from r and writes result to w incrementally,
+// so input of any size can be decoded with bounded memory.
+func UnpackReader(r io.Reader, w io.Writer) error {
+	if err := repeatgroup.UnpackReader(r, w); err != nil {
+		return fmt.Errorf("unable to unpack stream, %w", err)
+	}
+
+	return nil
+}
+
+// UnpackLimit decode input string like Unpack, but fails with *SizeLimitError
+// before allocation of result when it is longer then limit bytes.
+func UnpackLimit(input string, limit int) (string, error) {
+	gs, err := repeatgroup.ParseString(input)
+	if err != nil {
+		return "", fmt.Errorf("unable to parse string, %w", err)
+	}
+
+	return gs.UnpackLimit(limit)
+}
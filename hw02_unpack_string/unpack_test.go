@@ -1,8 +1,13 @@
 package hw02_unpack_string //nolint:golint,stylecheck
 
 import (
-	"fmt"
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
 	"testing"
+	"testing/iotest"
 
 	"github.com/stretchr/testify/require"
 
@@ -27,17 +32,17 @@ func TestUnpack(t *testing.T) {
 		{
 			input:    "3abc",
 			expected: "",
-			err:      fmt.Errorf("invalid format 3abc, offset: 1, digit can't be the first symbol"),
+			err:      &repeatgroup.ParseError{Kind: repeatgroup.KindLeadingDigit, Offset: 0, Index: 0, Size: 1, Input: "3abc"},
 		},
 		{
 			input:    "45",
 			expected: "",
-			err:      fmt.Errorf("invalid format 45, offset: 1, digit can't be the first symbol"),
+			err:      &repeatgroup.ParseError{Kind: repeatgroup.KindLeadingDigit, Offset: 0, Index: 0, Size: 1, Input: "45"},
 		},
 		{
 			input:    "aaa10b",
 			expected: "",
-			err:      fmt.Errorf("invalid format aaa10b, offset: 5, digit can't be the first symbol"),
+			err:      &repeatgroup.ParseError{Kind: repeatgroup.KindLeadingDigit, Offset: 4, Index: 4, Size: 1, Input: "aaa10b"},
 		},
 		{
 			input:    "",
@@ -100,7 +105,6 @@ func TestUnpackWithZeroByte(t *testing.T) {
 			name:     `zero byte first`,
 			input:    "\x00a2",
 			expected: "\x00aa",
-			// err: fmt.Errorf("invalid format \\x00a2, offset: 4, digit can't be the first symbol"),
 		},
 		{
 			name:     `zero byte last`,
@@ -224,3 +228,121 @@ func TestUnpackMyltiRuneCharacters(t *testing.T) {
 		})
 	}
 }
+
+func TestUnpackReader(t *testing.T) {
+	for _, tt := range [...]struct {
+		name     string
+		input    string
+		expected string
+		err      bool
+	}{
+		{
+			name:     `Empty string`,
+			input:    ``,
+			expected: ``,
+		},
+		{
+			name:     `Simple repeat`,
+			input:    `a4bc2d5e`,
+			expected: `aaaabccddddde`,
+		},
+		{
+			name:     `Escape`,
+			input:    `qwe\\\3\45`,
+			expected: `qwe\344444`,
+		},
+		{
+			name:     `Multy byte runes`,
+			input:    `ы2か3👨🏾‍🚀2`,
+			expected: `ыыかかか👨🏾‍🚀👨🏾‍🚀`,
+		},
+		{
+			name:     `Combining marks`,
+			input:    "a1e\u0301\u03012",
+			expected: "ae\u0301\u0301e\u0301\u0301",
+		},
+		{
+			name:  `Starts from digit`,
+			input: `3abc`,
+			err:   true,
+		},
+		{
+			name:     `Count after joiner`,
+			input:    "a\u200D2",
+			expected: "a\u200Da\u200D",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			// one byte reader splits multy byte runes between reads
+			for _, r := range [...]io.Reader{strings.NewReader(tt.input), iotest.OneByteReader(strings.NewReader(tt.input))} {
+				var out bytes.Buffer
+				err := UnpackReader(r, &out)
+				if tt.err {
+					require.Error(t, err)
+
+					continue
+				}
+
+				require.NoError(t, err)
+				require.Equal(t, tt.expected, out.String())
+			}
+		})
+	}
+
+	t.Run("large input", func(t *testing.T) {
+		input := strings.Repeat(`a9b\90`, 100_000)
+
+		var out bytes.Buffer
+		require.NoError(t, UnpackReader(strings.NewReader(input), &out))
+
+		expected, err := Unpack(input)
+		require.NoError(t, err)
+		require.Equal(t, expected, out.String())
+	})
+
+	t.Run("read error", func(t *testing.T) {
+		readErr := errors.New("connection reset")
+		r := io.MultiReader(strings.NewReader("a2b3"), iotest.ErrReader(readErr))
+
+		err := UnpackReader(r, ioutil.Discard)
+		require.True(t, errors.Is(err, readErr))
+	})
+
+	t.Run("write error", func(t *testing.T) {
+		r := strings.NewReader(strings.Repeat("a9", 10_000))
+
+		err := UnpackReader(r, errWriter{})
+		require.True(t, errors.Is(err, errWrite))
+	})
+}
+
+var errWrite = errors.New("disk full")
+
+type errWriter struct{}
+
+func (errWriter) Write([]byte) (int, error) {
+	return 0, errWrite
+}
+
+func TestUnpackParseError(t *testing.T) {
+	_, err := Unpack("ab45")
+
+	var pe *ParseError
+	require.True(t, errors.As(err, &pe), "unexpected error %v", err)
+	require.Equal(t, KindLeadingDigit, pe.Kind)
+	require.Equal(t, 3, pe.Offset)
+	require.Equal(t, "ab45\n   ^", pe.Snippet())
+}
+
+func TestUnpackLimit(t *testing.T) {
+	result, err := UnpackLimit("a4b2", 6)
+	require.NoError(t, err)
+	require.Equal(t, "aaaabb", result)
+
+	_, err = UnpackLimit("a9b9c9", 10)
+
+	var sle *SizeLimitError
+	require.True(t, errors.As(err, &sle), "unexpected error %v", err)
+	require.Equal(t, 27, sle.Size)
+	require.True(t, errors.Is(err, ErrSizeLimit))
+}
@@ -0,0 +1,415 @@
+// Command topwords prints the most frequent words of files with counts and percentages.
+//
+// Usage:
+//
+//	topwords [flags] [file or glob ...]
+//	topwords [flags] -compare base current
+//
+// Input is read from files or stdin when no files given or file is "-".
+// In compare mode base and current are files or globs of two corpora, only one of them may be stdin,
+// words of both tops are printed with their ranks and change of rank.
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	top "github.com/PrideSt/otus-golang/hw03_frequency_analysis"
+)
+
+const (
+	// ErrorCodeIO happens when input can't be read or output can't be written.
+	ErrorCodeIO = iota + 1
+	// ErrorCodeUsage happens when flags or arguments are invalid.
+	ErrorCodeUsage
+)
+
+// Output formats.
+const (
+	FormatTable = "table"
+	FormatJSON  = "json"
+	FormatCSV   = "csv"
+)
+
+var errUsage = errors.New("invalid usage")
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// config is parsed command line.
+type config struct {
+	n       int
+	format  string
+	compare bool
+	opts    top.Options
+	inputs  []string
+}
+
+// parseArgs parses flags, stop word files and tokenizer pattern.
+func parseArgs(args []string, stderr io.Writer) (config, error) {
+	flags := flag.NewFlagSet("topwords", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	n := flags.Int("n", 10, "count of words in top")
+	format := flags.String("format", FormatTable, "output format: table, json or csv")
+	compare := flags.Bool("compare", false, "compare tops of two corpora given as base and current arguments")
+	advanced := flags.Bool("advanced", false, "fold case, trim punctuation and skip dashes")
+	tokenizer := flags.String("tokenizer", "whitespace", "how text is split into words: whitespace or words (Unicode word boundaries)")
+	pattern := flags.String("pattern", "", "regular expression matching words, overrides -tokenizer")
+	stopWords := flags.String("stop-words", "", "comma separated files with stop words")
+	ngram := flags.Int("ngram", 1, "count phrases of this many words")
+	maxError := flags.Float64("max-error", 0, "approximate counting error relative to count of all words, 0 means exact")
+
+	if err := flags.Parse(args); err != nil {
+		return config{}, err
+	}
+
+	cfg := config{n: *n, format: *format, compare: *compare, inputs: flags.Args()}
+	switch {
+	case cfg.n <= 0:
+		return config{}, fmt.Errorf("%w: -n must be positive", errUsage)
+	case cfg.format != FormatTable && cfg.format != FormatJSON && cfg.format != FormatCSV:
+		return config{}, fmt.Errorf("%w: unknown format %q", errUsage, cfg.format)
+	case cfg.compare && len(cfg.inputs) != 2:
+		return config{}, fmt.Errorf("%w: -compare needs exactly two arguments", errUsage)
+	case cfg.compare && cfg.inputs[0] == "-" && cfg.inputs[1] == "-":
+		return config{}, fmt.Errorf("%w: stdin can't be both corpora of -compare", errUsage)
+	case *maxError < 0 || *maxError >= 1:
+		return config{}, fmt.Errorf("%w: -max-error must be in [0, 1)", errUsage)
+	}
+	if len(cfg.inputs) == 0 {
+		cfg.inputs = []string{"-"}
+	}
+
+	if *advanced {
+		cfg.opts = top.AdvancedOptions
+	}
+	cfg.opts.NGram = *ngram
+	cfg.opts.MaxError = *maxError
+
+	switch {
+	case *pattern != "":
+		t, err := top.NewRegexpTokenizer(*pattern)
+		if err != nil {
+			return config{}, fmt.Errorf("%w: %s", errUsage, err)
+		}
+		cfg.opts.Tokenizer = t
+	case *tokenizer == "words":
+		cfg.opts.Tokenizer = top.WordBoundaryTokenizer{}
+	case *tokenizer != "whitespace":
+		return config{}, fmt.Errorf("%w: unknown tokenizer %q", errUsage, *tokenizer)
+	}
+
+	if *stopWords != "" {
+		sw, err := top.LoadStopWordsFile(strings.Split(*stopWords, ",")...)
+		if err != nil {
+			return config{}, err
+		}
+		cfg.opts.StopWords = sw
+	}
+
+	return cfg, nil
+}
+
+// run counts words of files given in args or stdin, writes top to stdout and errors to stderr, returns exit code.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	cfg, err := parseArgs(args, stderr)
+	if err != nil {
+		// flag set reports its errors itself
+		var pathErr *os.PathError
+		if errors.As(err, &pathErr) {
+			_, _ = fmt.Fprintln(stderr, err)
+
+			return ErrorCodeIO
+		}
+		if errors.Is(err, errUsage) {
+			_, _ = fmt.Fprintln(stderr, err)
+		}
+
+		return ErrorCodeUsage
+	}
+
+	out := bufio.NewWriter(stdout)
+	if cfg.compare {
+		err = compareCorpora(cfg, stdin, out)
+	} else {
+		err = printTop(cfg, stdin, out)
+	}
+	if err == nil {
+		err = out.Flush()
+	}
+	if err != nil {
+		_, _ = fmt.Fprintln(stderr, err)
+		if errors.Is(err, errUsage) {
+			return ErrorCodeUsage
+		}
+
+		return ErrorCodeIO
+	}
+
+	return 0
+}
+
+// corpus counts words of all files matching patterns.
+func corpus(opts top.Options, stdin io.Reader, patterns ...string) (*top.Counter, error) {
+	c := top.NewCounter(opts)
+	for _, pattern := range patterns {
+		files, err := expand(pattern)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			if err := countFile(c, file, stdin); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return c, nil
+}
+
+// expand returns files matching glob pattern, pattern without matches is returned as is,
+// so missing file is reported on open.
+func expand(pattern string) ([]string, error) {
+	if pattern == "-" {
+		return []string{pattern}, nil
+	}
+
+	files, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q, %s", errUsage, pattern, err)
+	}
+	if len(files) == 0 {
+		return []string{pattern}, nil
+	}
+
+	return files, nil
+}
+
+// countFile counts words of file, "-" means stdin.
+func countFile(c *top.Counter, name string, stdin io.Reader) error {
+	if name == "-" {
+		if _, err := c.ReadFrom(stdin); err != nil {
+			return fmt.Errorf("-: %w", err)
+		}
+
+		return nil
+	}
+
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, err := c.ReadFrom(f); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+
+	return nil
+}
+
+// row is a word of top.
+type row struct {
+	Rank    int     `json:"rank"`
+	Word    string  `json:"word"`
+	Count   int     `json:"count"`
+	Percent float64 `json:"percent"`
+}
+
+// rows converts top of counter into rows, percent is relative to count of all words.
+func rows(c *top.Counter, n int) []row {
+	total := c.Total()
+	counts := c.Top(n)
+	result := make([]row, 0, len(counts))
+	for i, wc := range counts {
+		result = append(result, row{
+			Rank:    i + 1,
+			Word:    wc.Word,
+			Count:   wc.Count,
+			Percent: 100 * float64(wc.Count) / float64(total),
+		})
+	}
+
+	return result
+}
+
+func printTop(cfg config, stdin io.Reader, w io.Writer) error {
+	c, err := corpus(cfg.opts, stdin, cfg.inputs...)
+	if err != nil {
+		return err
+	}
+	words := rows(c, cfg.n)
+
+	switch cfg.format {
+	case FormatJSON:
+		return writeJSON(w, words)
+	case FormatCSV:
+		records := make([][]string, 0, len(words)+1)
+		records = append(records, []string{"rank", "word", "count", "percent"})
+		for _, r := range words {
+			records = append(records, []string{itoa(r.Rank), r.Word, itoa(r.Count), formatPercent(r.Percent)})
+		}
+
+		return writeCSV(w, records)
+	default:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(tw, "RANK\tWORD\tCOUNT\tPERCENT")
+		for _, r := range words {
+			_, _ = fmt.Fprintf(tw, "%d\t%s\t%d\t%s%%\n", r.Rank, r.Word, r.Count, formatPercent(r.Percent))
+		}
+
+		return tw.Flush()
+	}
+}
+
+// Change of word rank between base and current corpora.
+const (
+	ChangeUp   = "up"
+	ChangeDown = "down"
+	ChangeSame = "same"
+	ChangeNew  = "new"
+	ChangeGone = "gone"
+)
+
+// diffRow is a word of base or current top, zero rank and count mean word isn't in that top.
+type diffRow struct {
+	Word      string `json:"word"`
+	BaseRank  int    `json:"base_rank"`
+	Rank      int    `json:"rank"`
+	BaseCount int    `json:"base_count"`
+	Count     int    `json:"count"`
+	// Shift is count of positions word moved up, negative when it moved down.
+	Shift  int    `json:"shift"`
+	Change string `json:"change"`
+}
+
+// diff returns words of both tops ordered by current rank, words gone from top are the last ones.
+func diff(base, current []row) []diffRow {
+	byWord := make(map[string]*diffRow, len(base)+len(current))
+	result := make([]diffRow, 0, len(base)+len(current))
+	for _, r := range current {
+		result = append(result, diffRow{Word: r.Word, Rank: r.Rank, Count: r.Count, Change: ChangeNew})
+	}
+	for i := range result {
+		byWord[result[i].Word] = &result[i]
+	}
+
+	for _, r := range base {
+		d, ok := byWord[r.Word]
+		if !ok {
+			result = append(result, diffRow{Word: r.Word, BaseRank: r.Rank, BaseCount: r.Count, Change: ChangeGone})
+
+			continue
+		}
+
+		d.BaseRank, d.BaseCount = r.Rank, r.Count
+		d.Shift = d.BaseRank - d.Rank
+		switch {
+		case d.Shift > 0:
+			d.Change = ChangeUp
+		case d.Shift < 0:
+			d.Change = ChangeDown
+		default:
+			d.Change = ChangeSame
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if (a.Rank == 0) != (b.Rank == 0) {
+			return a.Rank != 0
+		}
+		if a.Rank != b.Rank {
+			return a.Rank < b.Rank
+		}
+
+		return a.BaseRank < b.BaseRank
+	})
+
+	return result
+}
+
+func compareCorpora(cfg config, stdin io.Reader, w io.Writer) error {
+	base, err := corpus(cfg.opts, stdin, cfg.inputs[0])
+	if err != nil {
+		return err
+	}
+	current, err := corpus(cfg.opts, stdin, cfg.inputs[1])
+	if err != nil {
+		return err
+	}
+	changes := diff(rows(base, cfg.n), rows(current, cfg.n))
+
+	switch cfg.format {
+	case FormatJSON:
+		return writeJSON(w, changes)
+	case FormatCSV:
+		records := make([][]string, 0, len(changes)+1)
+		records = append(records, []string{"word", "base_rank", "rank", "base_count", "count", "shift", "change"})
+		for _, d := range changes {
+			records = append(records, []string{
+				d.Word, itoa(d.BaseRank), itoa(d.Rank), itoa(d.BaseCount), itoa(d.Count), itoa(d.Shift), d.Change,
+			})
+		}
+
+		return writeCSV(w, records)
+	default:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(tw, "WORD\tBASE RANK\tRANK\tBASE COUNT\tCOUNT\tCHANGE")
+		for _, d := range changes {
+			change := d.Change
+			if d.Shift != 0 {
+				change = fmt.Sprintf("%+d", d.Shift)
+			}
+			_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+				d.Word, optional(d.BaseRank), optional(d.Rank), optional(d.BaseCount), optional(d.Count), change)
+		}
+
+		return tw.Flush()
+	}
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(v)
+}
+
+func writeCSV(w io.Writer, records [][]string) error {
+	cw := csv.NewWriter(w)
+	if err := cw.WriteAll(records); err != nil {
+		return fmt.Errorf("unable to write csv, %w", err)
+	}
+
+	return nil
+}
+
+func itoa(i int) string {
+	return strconv.Itoa(i)
+}
+
+// optional formats i, zero means absent value.
+func optional(i int) string {
+	if i == 0 {
+		return "-"
+	}
+
+	return strconv.Itoa(i)
+}
+
+func formatPercent(p float64) string {
+	return strconv.FormatFloat(p, 'f', 2, 64)
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"old/1.txt":     "cat dog cat bird",
+		"old/2.txt":     "cat dog",
+		"new/1.txt":     "dog dog dog cat fish",
+		"stopwords.txt": "# animals we don't count\nbird\n",
+	} {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o700))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+
+	for _, tt := range [...]struct {
+		name   string
+		args   []string
+		stdin  string
+		stdout string
+		stderr string
+		code   int
+	}{
+		{
+			name:  `Table from stdin`,
+			args:  []string{"-n", "2"},
+			stdin: "b a c b a",
+			stdout: "RANK  WORD  COUNT  PERCENT\n" +
+				"1     a     2      40.00%\n" +
+				"2     b     2      40.00%\n",
+		},
+		{
+			name:   `CSV from glob`,
+			args:   []string{"-format", "csv", filepath.Join(dir, "old", "*.txt")},
+			stdout: "rank,word,count,percent\n1,cat,3,50.00\n2,dog,2,33.33\n3,bird,1,16.67\n",
+		},
+		{
+			name:   `N more then count of words`,
+			args:   []string{"-format", "csv", "-n", "1000000000000"},
+			stdin:  "b a b",
+			stdout: "rank,word,count,percent\n1,b,2,66.67\n2,a,1,33.33\n",
+		},
+		{
+			name:   `Stop words and advanced normalization`,
+			args:   []string{"-format", "csv", "-advanced", "-stop-words", filepath.Join(dir, "stopwords.txt")},
+			stdin:  "Bird, cat! Cat",
+			stdout: "rank,word,count,percent\n1,cat,2,100.00\n",
+		},
+		{
+			name:   `Bigrams`,
+			args:   []string{"-format", "csv", "-ngram", "2", "-n", "1"},
+			stdin:  "big cat big cat",
+			stdout: "rank,word,count,percent\n1,big cat,2,66.67\n",
+		},
+		{
+			name:   `Regexp tokenizer`,
+			args:   []string{"-format", "csv", "-pattern", `\d+`},
+			stdin:  "a1 b22 c1",
+			stdout: "rank,word,count,percent\n1,1,2,66.67\n2,22,1,33.33\n",
+		},
+		{
+			name: `Compare`,
+			args: []string{"-compare", "-n", "2", filepath.Join(dir, "old", "*.txt"), filepath.Join(dir, "new", "1.txt")},
+			stdout: "WORD  BASE RANK  RANK  BASE COUNT  COUNT  CHANGE\n" +
+				"dog   2          1     2           3      +1\n" +
+				"cat   1          2     3           1      -1\n",
+		},
+		{
+			name: `Compare with new and gone words`,
+			args: []string{"-compare", "-format", "csv", "-n", "3", filepath.Join(dir, "old", "*.txt"), filepath.Join(dir, "new", "1.txt")},
+			stdout: "word,base_rank,rank,base_count,count,shift,change\n" +
+				"dog,2,1,2,3,1,up\n" +
+				"cat,1,2,3,1,-1,down\n" +
+				"fish,0,3,0,1,0,new\n" +
+				"bird,3,0,1,0,0,gone\n",
+		},
+		{
+			name:   `Compare needs two corpora`,
+			args:   []string{"-compare", "a.txt"},
+			stderr: "invalid usage: -compare needs exactly two arguments\n",
+			code:   ErrorCodeUsage,
+		},
+		{
+			name:   `Compare stdin with itself`,
+			args:   []string{"-compare", "-", "-"},
+			stdin:  "a b a",
+			stderr: "invalid usage: stdin can't be both corpora of -compare\n",
+			code:   ErrorCodeUsage,
+		},
+		{
+			name:   `Unknown format`,
+			args:   []string{"-format", "xml"},
+			stderr: "invalid usage: unknown format \"xml\"\n",
+			code:   ErrorCodeUsage,
+		},
+		{
+			name:   `Invalid pattern`,
+			args:   []string{"-pattern", "("},
+			stderr: "invalid usage: invalid token pattern \"(\", error parsing regexp: missing closing ): `(`\n",
+			code:   ErrorCodeUsage,
+		},
+		{
+			name:   `Missing file`,
+			args:   []string{filepath.Join(dir, "missing.txt")},
+			stderr: "open " + filepath.Join(dir, "missing.txt") + ": no such file or directory\n",
+			code:   ErrorCodeIO,
+		},
+		{
+			name:   `Missing stop words file`,
+			args:   []string{"-stop-words", filepath.Join(dir, "missing.txt")},
+			stderr: "unable to open stop words file, open " + filepath.Join(dir, "missing.txt") + ": no such file or directory\n",
+			code:   ErrorCodeIO,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := run(tt.args, strings.NewReader(tt.stdin), &stdout, &stderr)
+			require.Equal(t, tt.code, code)
+			require.Equal(t, tt.stdout, stdout.String())
+			require.Equal(t, tt.stderr, stderr.String())
+		})
+	}
+
+	t.Run("json", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		code := run([]string{"-format", "json", "-n", "1"}, strings.NewReader("a b a"), &stdout, &stderr)
+		require.Equal(t, 0, code)
+
+		var result []row
+		require.NoError(t, json.Unmarshal(stdout.Bytes(), &result))
+		require.Len(t, result, 1)
+		require.Equal(t, row{Rank: 1, Word: "a", Count: 2, Percent: 100 * 2. / 3}, result[0])
+	})
+}
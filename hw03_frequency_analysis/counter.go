@@ -0,0 +1,194 @@
+package hw03_frequency_analysis //nolint:golint,stylecheck
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// readBufferSize is a size of chunks read by Counter.ReadFrom.
+const readBufferSize = 32 * 1024
+
+// DefaultMaxTokenLength is a limit of field length used when Options.MaxTokenLength is zero.
+const DefaultMaxTokenLength = 64 * 1024
+
+// Counter counts words of text given in chunks, word may be split between chunks.
+// Counter is safe for concurrent use, so top may be requested while text is written.
+type Counter struct {
+	opts Options
+
+	mu     sync.Mutex
+	counts tally
+	// tail is the last field of written text, it may continue in the next chunk
+	tail []byte
+	// skipping is true while the last field is longer then limit, tail keeps only
+	// its last bytes then, they may be a start of space rune
+	skipping bool
+	// window contains the last NGram-1 words, phrase may continue in the next chunk
+	window []string
+	total  int
+}
+
+// NewCounter creates Counter which normalizes words by opts.
+func NewCounter(opts Options) *Counter {
+	if opts.MaxTokenLength <= 0 {
+		opts.MaxTokenLength = DefaultMaxTokenLength
+	}
+
+	return &Counter{
+		opts:   opts,
+		counts: newTally(opts),
+	}
+}
+
+// Write implements io.Writer, it counts all complete words of p,
+// the last field is counted by the next Write, Flush or ReadFrom.
+// Fields longer then Options.MaxTokenLength aren't counted.
+func (c *Counter) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// tail has no complete space rune, so only p and incomplete rune at the end of tail are scanned
+	start := len(c.tail) - (utf8.UTFMax - 1)
+	if start < 0 {
+		start = 0
+	}
+
+	data := append(c.tail, p...)
+	end := lastSpaceEnd(data[start:])
+	if end > 0 {
+		end += start
+		from := 0
+		if c.skipping {
+			// the rest of too long field is dropped
+			from = firstSpaceEnd(data[:end])
+			c.skipping = false
+		}
+		c.count(data[from:end])
+	}
+
+	rest := data[end:]
+	if len(rest) > c.opts.MaxTokenLength {
+		if !c.skipping {
+			// phrases don't join words before and after skipped field
+			c.window = c.window[:0]
+		}
+		c.skipping = true
+		if keep := utf8.UTFMax - 1; len(rest) > keep {
+			rest = rest[len(rest)-keep:]
+		}
+	}
+
+	// data may share memory with tail, so it is copied to the start of buffer
+	c.tail = append(data[:0], rest...)
+
+	return len(p), nil
+}
+
+// Flush counts the last field written, it is needed when text doesn't end with space.
+// Phrases don't join words written before and after Flush.
+func (c *Counter) Flush() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.skipping {
+		c.count(c.tail)
+	}
+	c.skipping = false
+	c.tail = c.tail[:0]
+	c.window = c.window[:0]
+}
+
+// ReadFrom implements io.ReaderFrom, it counts words of r until EOF, the last field is counted too.
+func (c *Counter) ReadFrom(r io.Reader) (int64, error) {
+	var total int64
+	buffer := make([]byte, readBufferSize)
+
+	for {
+		n, err := r.Read(buffer)
+		if n > 0 {
+			_, _ = c.Write(buffer[:n])
+			total += int64(n)
+		}
+
+		if errors.Is(err, io.EOF) {
+			c.Flush()
+
+			return total, nil
+		}
+		if err != nil {
+			return total, fmt.Errorf("unable to read text, %w", err)
+		}
+	}
+}
+
+// Top returns n the most frequent words counted so far, words with equal count are ordered lexicographically.
+func (c *Counter) Top(n int) []WordCount {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.counts.top(n)
+}
+
+// Total returns count of all words or phrases counted so far, it is exact in approximate mode too.
+func (c *Counter) Total() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.total
+}
+
+// count adds words or phrases of complete fields to counts.
+func (c *Counter) count(text []byte) {
+	c.opts.words(string(text), c.add)
+}
+
+// add counts word or phrase ending with word.
+func (c *Counter) add(word string) {
+	if c.opts.NGram < 2 {
+		c.counts.add(word)
+		c.total++
+
+		return
+	}
+
+	c.window = append(c.window, word)
+	if len(c.window) < c.opts.NGram {
+		return
+	}
+
+	c.counts.add(strings.Join(c.window, " "))
+	c.total++
+	c.window = append(c.window[:0], c.window[1:]...)
+}
+
+// lastSpaceEnd returns index right after the last space rune of data, 0 when there is no space.
+// Incomplete rune at the end of data isn't a space, so it stays in tail until the next chunk.
+func lastSpaceEnd(data []byte) int {
+	for end := len(data); end > 0; {
+		r, size := utf8.DecodeLastRune(data[:end])
+		if unicode.IsSpace(r) {
+			return end
+		}
+		end -= size
+	}
+
+	return 0
+}
+
+// firstSpaceEnd returns index right after the first space rune of data, 0 when there is no space.
+func firstSpaceEnd(data []byte) int {
+	for start := 0; start < len(data); {
+		r, size := utf8.DecodeRune(data[start:])
+		start += size
+		if unicode.IsSpace(r) {
+			return start
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,153 @@
+package hw03_frequency_analysis //nolint:golint
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCounter(t *testing.T) {
+	expected := NewCounter(AdvancedOptions)
+	_, err := expected.ReadFrom(strings.NewReader(text))
+	require.NoError(t, err)
+
+	t.Run("same as TopN", func(t *testing.T) {
+		top := expected.Top(10)
+		words := make([]string, 0, len(top))
+		for _, wc := range top {
+			words = append(words, wc.Word)
+		}
+		require.Equal(t, TopN(text, 10, AdvancedOptions), words)
+	})
+
+	t.Run("one byte reader", func(t *testing.T) {
+		c := NewCounter(AdvancedOptions)
+		n, err := c.ReadFrom(iotest.OneByteReader(strings.NewReader(text)))
+		require.NoError(t, err)
+		require.Equal(t, int64(len(text)), n)
+		require.Equal(t, expected.Top(100), c.Top(100))
+	})
+
+	t.Run("split at every byte", func(t *testing.T) {
+		// em space and non breaking space are multi byte space runes
+		input := "Нога нога-то\u2003нога\tрука\u00a0рука"
+		for i := 0; i <= len(input); i++ {
+			c := NewCounter(Options{})
+			_, _ = c.Write([]byte(input[:i]))
+			_, _ = c.Write([]byte(input[i:]))
+			c.Flush()
+			require.Equal(t, []WordCount{{"рука", 2}, {"Нога", 1}, {"нога", 1}, {"нога-то", 1}}, c.Top(10), "split at %d", i)
+		}
+	})
+
+	t.Run("last field waits for flush", func(t *testing.T) {
+		c := NewCounter(Options{})
+		_, _ = io.WriteString(c, "cat dog ca")
+		require.Equal(t, []WordCount{{"cat", 1}, {"dog", 1}}, c.Top(10))
+
+		_, _ = io.WriteString(c, "t")
+		c.Flush()
+		require.Equal(t, []WordCount{{"cat", 2}, {"dog", 1}}, c.Top(10))
+		require.Equal(t, 3, c.Total())
+	})
+
+	t.Run("phrase split between writes", func(t *testing.T) {
+		c := NewCounter(Options{NGram: 2})
+		_, _ = io.WriteString(c, "big ca")
+		_, _ = io.WriteString(c, "t big cat ")
+		_, _ = io.WriteString(c, "big")
+		c.Flush()
+		require.Equal(t, []WordCount{{"big cat", 2}, {"cat big", 2}}, c.Top(10))
+
+		// flush ends phrase
+		_, _ = io.WriteString(c, "cat")
+		c.Flush()
+		require.Equal(t, []WordCount{{"big cat", 2}, {"cat big", 2}}, c.Top(10))
+		require.Equal(t, 4, c.Total())
+	})
+
+	t.Run("too long field is skipped", func(t *testing.T) {
+		c := NewCounter(Options{MaxTokenLength: 16})
+		_, _ = io.WriteString(c, "cat ")
+		for i := 0; i < 1000; i++ {
+			_, _ = io.WriteString(c, "aaaaaaaaaa")
+			require.LessOrEqual(t, len(c.tail), 26, "tail must not grow")
+		}
+		// em space is split between writes while long field is skipped
+		_, _ = io.WriteString(c, "aaa\xe2\x80")
+		_, _ = io.WriteString(c, "\x83dog aaaaaaaaaaaaaaaaaaaa")
+		c.Flush()
+		require.Equal(t, []WordCount{{"cat", 1}, {"dog", 1}}, c.Top(10))
+
+		// field of limit length is counted
+		_, _ = io.WriteString(c, strings.Repeat("b", 8))
+		_, _ = io.WriteString(c, strings.Repeat("b", 8)+" ")
+		require.Equal(t, WordCount{strings.Repeat("b", 16), 1}, c.Top(10)[0])
+	})
+
+	t.Run("limit less then rune", func(t *testing.T) {
+		for _, limit := range []int{1, 2} {
+			c := NewCounter(Options{MaxTokenLength: limit})
+			_, _ = io.WriteString(c, "cat aa")
+			// em space is split between writes while long field is skipped
+			_, _ = io.WriteString(c, "aaa\xe2")
+			_, _ = io.WriteString(c, "\x80\x83d")
+			c.Flush()
+			require.Equal(t, []WordCount{{"cat", 1}, {"d", 1}}, c.Top(10), "limit %d", limit)
+		}
+	})
+
+	t.Run("phrase doesn't span skipped field", func(t *testing.T) {
+		c := NewCounter(Options{NGram: 2, MaxTokenLength: 4})
+		_, _ = io.WriteString(c, "cat aaaaaaaa")
+		_, _ = io.WriteString(c, "aaaa dog fish")
+		c.Flush()
+		require.Equal(t, []WordCount{{"dog fish", 1}}, c.Top(10))
+	})
+
+	t.Run("read error", func(t *testing.T) {
+		errRead := errors.New("read failed")
+		c := NewCounter(Options{})
+		_, err := c.ReadFrom(io.MultiReader(strings.NewReader("cat "), errReader{errRead}))
+		require.True(t, errors.Is(err, errRead))
+		require.Equal(t, []WordCount{{"cat", 1}}, c.Top(10))
+	})
+
+	t.Run("top while writing", func(t *testing.T) {
+		c := NewCounter(Options{})
+
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				_, _ = io.WriteString(c, "cat dog cat ")
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				top := c.Top(1)
+				if len(top) > 0 {
+					require.Equal(t, "cat", top[0].Word)
+				}
+			}
+		}()
+		wg.Wait()
+
+		require.Equal(t, []WordCount{{"cat", 2000}, {"dog", 1000}}, c.Top(10))
+	})
+}
+
+type errReader struct {
+	err error
+}
+
+func (r errReader) Read(_ []byte) (int, error) {
+	return 0, r.err
+}
@@ -1,5 +1,14 @@
-module github.com/fixme_my_friend/hw03_frequency_analysis
+module github.com/PrideSt/otus-golang/hw03_frequency_analysis
 
-go 1.14
+go 1.18
 
-require github.com/stretchr/testify v1.5.0
+require (
+	github.com/rivo/uniseg v0.4.7
+	github.com/stretchr/testify v1.5.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	gopkg.in/yaml.v2 v2.2.2 // indirect
+)
@@ -0,0 +1,116 @@
+package hw03_frequency_analysis //nolint:golint,stylecheck
+
+import (
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// parallelTop counts words of text by opts.Workers goroutines and returns n the most frequent of them.
+// Text is split at spaces into parts, every part is counted in local maps, one map per shard of words.
+// Then every shard is merged over all parts and its local top is selected, the final top is selected
+// from local ones by the same heap.
+func parallelTop(text string, n int, opts Options) []WordCount {
+	parts := splitAtSpaces(text, opts.Workers)
+	shards := len(parts)
+
+	// local[part][shard] contains counts of shard words in part
+	local := make([][]exactTally, len(parts))
+	var wg sync.WaitGroup
+	wg.Add(len(parts))
+	for i, part := range parts {
+		go func(i int, part string) {
+			defer wg.Done()
+
+			counts := make([]exactTally, shards)
+			for shard := range counts {
+				counts[shard] = make(exactTally)
+			}
+			opts.words(part, func(word string) {
+				counts[hashString(word)%uint32(shards)].add(word)
+			})
+			local[i] = counts
+		}(i, part)
+	}
+	wg.Wait()
+
+	tops := make([][]WordCount, shards)
+	wg.Add(shards)
+	for shard := 0; shard < shards; shard++ {
+		go func(shard int) {
+			defer wg.Done()
+
+			merged := local[0][shard]
+			for _, counts := range local[1:] {
+				for word, cnt := range counts[shard] {
+					merged[word] += cnt
+				}
+			}
+			tops[shard] = topCounts(merged, n)
+		}(shard)
+	}
+	wg.Wait()
+
+	// shards contain different words, so local tops are just joined
+	candidates := make(exactTally)
+	for _, top := range tops {
+		for _, wc := range top {
+			candidates[wc.Word] = wc.Count
+		}
+	}
+
+	return topCounts(candidates, n)
+}
+
+// splitAtSpaces splits text into at most parts pieces of similar size, pieces are split at space runes.
+func splitAtSpaces(text string, parts int) []string {
+	if parts < 1 {
+		parts = 1
+	}
+
+	result := make([]string, 0, parts)
+	start := 0
+	for i := 1; i < parts && start < len(text); i++ {
+		end := start + (len(text)-start)/(parts-i+1)
+		if end <= start {
+			end = start + 1
+		}
+
+		// move end right after the nearest space rune
+		for end < len(text) && !utf8.RuneStart(text[end]) {
+			end++
+		}
+		if sp := strings.IndexFunc(text[end:], unicode.IsSpace); sp >= 0 {
+			_, size := utf8.DecodeRuneInString(text[end+sp:])
+			end += sp + size
+		} else {
+			end = len(text)
+		}
+
+		result = append(result, text[start:end])
+		start = end
+	}
+
+	if start < len(text) || len(result) == 0 {
+		result = append(result, text[start:])
+	}
+
+	return result
+}
+
+// hashString is FNV-1a hash of s.
+func hashString(s string) uint32 {
+	const (
+		offset32 = 2166136261
+		prime32  = 16777619
+	)
+
+	h := uint32(offset32)
+	for i := 0; i < len(s); i++ {
+		h ^= uint32(s[i])
+		h *= prime32
+	}
+
+	return h
+}
@@ -0,0 +1,99 @@
+package hw03_frequency_analysis //nolint:golint
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitAtSpaces(t *testing.T) {
+	for _, tt := range [...]struct {
+		name     string
+		text     string
+		parts    int
+		expected []string
+	}{
+		{
+			name:     "empty text",
+			parts:    4,
+			expected: []string{""},
+		},
+		{
+			name:     "single part",
+			text:     "a b c",
+			parts:    1,
+			expected: []string{"a b c"},
+		},
+		{
+			name:     "no spaces",
+			text:     "abcdef",
+			parts:    3,
+			expected: []string{"abcdef"},
+		},
+		{
+			name:     "equal parts",
+			text:     "aa bb cc dd",
+			parts:    2,
+			expected: []string{"aa bb ", "cc dd"},
+		},
+		{
+			name:     "more parts then words",
+			text:     "a b",
+			parts:    8,
+			expected: []string{"a ", "b"},
+		},
+		{
+			name:     "multi byte space",
+			text:     "ыы\u2003ыы\u2003ыы",
+			parts:    3,
+			expected: []string{"ыы\u2003ыы\u2003", "ыы"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			parts := splitAtSpaces(tt.text, tt.parts)
+			require.Equal(t, tt.expected, parts)
+			require.Equal(t, tt.text, strings.Join(parts, ""))
+		})
+	}
+}
+
+func TestParallelTop(t *testing.T) {
+	large := zipfText(7, 50_000, 5_000)
+
+	for _, input := range []string{"", "a", text, large} {
+		for _, opts := range []Options{{}, AdvancedOptions} {
+			expected := TopN(input, 20, opts)
+			for _, workers := range []int{2, 3, 8, 64} {
+				opts.Workers = workers
+				require.Equal(t, expected, TopN(input, 20, opts), "workers %d", workers)
+			}
+		}
+	}
+}
+
+// BenchmarkTopN compares sequential counting (workers=1) with parallel one, parallel counting
+// needs several CPUs to win, e.g. go test -bench=TopN -cpu=1,4,8.
+func BenchmarkTopN(b *testing.B) {
+	text := zipfText(42, 2_000_000, 200_000)
+	b.SetBytes(int64(len(text)))
+
+	for _, workers := range []int{1, 2, 4, 8} {
+		b.Run("workers="+strconv.Itoa(workers), func(b *testing.B) {
+			opts := AdvancedOptions
+			opts.Workers = workers
+			for i := 0; i < b.N; i++ {
+				TopN(text, 10, opts)
+			}
+		})
+	}
+
+	b.Run("approximate", func(b *testing.B) {
+		opts := AdvancedOptions
+		opts.MaxError = 0.0001
+		for i := 0; i < b.N; i++ {
+			TopN(text, 10, opts)
+		}
+	})
+}
@@ -0,0 +1,135 @@
+package hw03_frequency_analysis //nolint:golint,stylecheck
+
+import (
+	"container/heap"
+	"math"
+	"sort"
+	"strings"
+)
+
+// tally stores counts of words.
+type tally interface {
+	add(word string)
+	top(n int) []WordCount
+}
+
+// exactTally counts every word, memory usage grows with count of distinct words.
+type exactTally map[string]int
+
+// add counts word, it is copied when added to the map first time,
+// so the map doesn't hold big text word is a part of.
+func (t exactTally) add(word string) {
+	if _, ok := t[word]; !ok {
+		word = strings.Clone(word)
+	}
+	t[word]++
+}
+
+func (t exactTally) top(n int) []WordCount {
+	return topCounts(t, n)
+}
+
+// newTally creates exact or approximate storage of counts depending on opts.
+func newTally(opts Options) tally {
+	if opts.MaxError > 0 {
+		return newSpaceSaving(opts.MaxError)
+	}
+
+	return make(exactTally)
+}
+
+// spaceSaving approximates counts of the most frequent words by Space-Saving algorithm,
+// it keeps at most ceil(1/maxError) words in min-heap ordered by count. When heap is full,
+// a new word replaces the least frequent one and inherits its count, so counts are overestimated
+// by at most maxError * (count of all words) and every word more frequent then that is in heap.
+// @see Metwally, Agrawal, El Abbadi "Efficient Computation of Frequent and Top-k Elements in Data Streams"
+type spaceSaving struct {
+	capacity int
+	entries  []WordCount
+	index    map[string]int // position of word in entries
+}
+
+func newSpaceSaving(maxError float64) *spaceSaving {
+	capacity := 1
+	if maxError < 1 {
+		capacity = int(math.Ceil(1 / maxError))
+	}
+
+	return &spaceSaving{
+		capacity: capacity,
+		index:    make(map[string]int, capacity),
+	}
+}
+
+func (s *spaceSaving) add(word string) {
+	if i, ok := s.index[word]; ok {
+		s.entries[i].Count++
+		heap.Fix(s, i)
+
+		return
+	}
+
+	word = strings.Clone(word)
+	if len(s.entries) < s.capacity {
+		heap.Push(s, WordCount{word, 1})
+
+		return
+	}
+
+	// the least frequent word is evicted, the new one may have appeared up to its count times
+	delete(s.index, s.entries[0].Word)
+	s.entries[0] = WordCount{word, s.entries[0].Count + 1}
+	s.index[word] = 0
+	heap.Fix(s, 0)
+}
+
+func (s *spaceSaving) top(n int) []WordCount {
+	if n <= 0 {
+		return nil
+	}
+
+	all := make([]WordCount, len(s.entries))
+	copy(all, s.entries)
+	sort.Slice(all, func(i, j int) bool {
+		return less(all[i], all[j])
+	})
+
+	if len(all) > n {
+		all = all[:n]
+	}
+
+	return all
+}
+
+// Len implements heap.Interface.
+func (s *spaceSaving) Len() int {
+	return len(s.entries)
+}
+
+// Less implements heap.Interface.
+func (s *spaceSaving) Less(i, j int) bool {
+	return s.entries[i].Count < s.entries[j].Count
+}
+
+// Swap implements heap.Interface.
+func (s *spaceSaving) Swap(i, j int) {
+	s.entries[i], s.entries[j] = s.entries[j], s.entries[i]
+	s.index[s.entries[i].Word] = i
+	s.index[s.entries[j].Word] = j
+}
+
+// Push implements heap.Interface.
+func (s *spaceSaving) Push(x interface{}) {
+	wc := x.(WordCount)
+	s.index[wc.Word] = len(s.entries)
+	s.entries = append(s.entries, wc)
+}
+
+// Pop implements heap.Interface.
+func (s *spaceSaving) Pop() interface{} {
+	last := s.entries[len(s.entries)-1]
+	s.entries = s.entries[:len(s.entries)-1]
+	delete(s.index, last.Word)
+
+	return last
+}
@@ -0,0 +1,109 @@
+package hw03_frequency_analysis //nolint:golint
+
+import (
+	"math/rand"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// zipfText generates text of count words with Zipf distribution, like natural language has.
+func zipfText(seed int64, count int, vocabulary uint64) string {
+	rnd := rand.New(rand.NewSource(seed)) //nolint:gosec
+	zipf := rand.NewZipf(rnd, 1.1, 1, vocabulary-1)
+
+	var sb strings.Builder
+	for i := 0; i < count; i++ {
+		sb.WriteString("w")
+		sb.WriteString(strconv.FormatUint(zipf.Uint64(), 10))
+		sb.WriteByte(' ')
+	}
+
+	return sb.String()
+}
+
+func TestSpaceSaving(t *testing.T) {
+	t.Run("exact while capacity is enough", func(t *testing.T) {
+		s := newSpaceSaving(0.25)
+		for _, word := range strings.Fields("a b a c a b d") {
+			s.add(word)
+		}
+		require.Equal(t, []WordCount{{"a", 3}, {"b", 2}, {"c", 1}, {"d", 1}}, s.top(10))
+	})
+
+	t.Run("new word inherits count of evicted one", func(t *testing.T) {
+		s := newSpaceSaving(0.5)
+		for _, word := range strings.Fields("a a a b c") {
+			s.add(word)
+		}
+		require.Equal(t, []WordCount{{"a", 3}, {"c", 2}}, s.top(10))
+		require.Len(t, s.index, 2)
+	})
+}
+
+func TestApproximateAccuracy(t *testing.T) {
+	const (
+		words    = 200_000
+		maxError = 0.001
+	)
+	text := zipfText(42, words, 100_000)
+
+	exact := NewCounter(Options{})
+	approx := NewCounter(Options{MaxError: maxError})
+	for _, c := range []*Counter{exact, approx} {
+		_, err := c.ReadFrom(strings.NewReader(text))
+		require.NoError(t, err)
+	}
+
+	exactCounts := make(map[string]int)
+	for _, wc := range exact.Top(words) {
+		exactCounts[wc.Word] = wc.Count
+	}
+	require.Greater(t, len(exactCounts), 10_000, "vocabulary must be much bigger then capacity")
+
+	t.Run("memory is bounded", func(t *testing.T) {
+		require.Len(t, approx.Top(words), 1000)
+	})
+
+	t.Run("counts are overestimated within error bound", func(t *testing.T) {
+		for _, wc := range approx.Top(words) {
+			require.GreaterOrEqual(t, wc.Count, exactCounts[wc.Word], wc.Word)
+			require.LessOrEqual(t, wc.Count, exactCounts[wc.Word]+int(maxError*words), wc.Word)
+		}
+	})
+
+	t.Run("frequent words are found", func(t *testing.T) {
+		found := make(map[string]bool)
+		for _, wc := range approx.Top(words) {
+			found[wc.Word] = true
+		}
+		for word, cnt := range exactCounts {
+			if cnt > int(maxError*words) {
+				require.True(t, found[word], "word %s with count %d is missed", word, cnt)
+			}
+		}
+	})
+
+	t.Run("top matches exact one", func(t *testing.T) {
+		require.Equal(t, exact.Top(10), approx.Top(10))
+
+		// precision of the top 100 words
+		expected := make(map[string]bool)
+		for _, wc := range exact.Top(100) {
+			expected[wc.Word] = true
+		}
+		matched := 0
+		for _, wc := range approx.Top(100) {
+			if expected[wc.Word] {
+				matched++
+			}
+		}
+		require.GreaterOrEqual(t, matched, 90)
+	})
+
+	t.Run("TopN", func(t *testing.T) {
+		require.Equal(t, TopN(text, 10, Options{}), TopN(text, 10, Options{MaxError: maxError}))
+	})
+}
@@ -0,0 +1,71 @@
+package hw03_frequency_analysis //nolint:golint,stylecheck
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// StopWords is a set of words which aren't counted.
+type StopWords map[string]struct{}
+
+// Contains returns true when word is in set.
+func (sw StopWords) Contains(word string) bool {
+	_, ok := sw[word]
+
+	return ok
+}
+
+// LoadStopWords reads stop words from r, one word per line, empty lines and lines starting with # are skipped.
+// Words are compared with normalized ones, so lists for FoldCase must be in lower case.
+func LoadStopWords(r io.Reader) (StopWords, error) {
+	sw := make(StopWords)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		word := strings.TrimSpace(scanner.Text())
+		if word == "" || strings.HasPrefix(word, "#") {
+			continue
+		}
+		sw[word] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read stop words, %w", err)
+	}
+
+	return sw, nil
+}
+
+// LoadStopWordsFile reads stop words from files and joins them in one set,
+// lists for Russian and English are in stopwords directory.
+func LoadStopWordsFile(paths ...string) (StopWords, error) {
+	sw := make(StopWords)
+	for _, path := range paths {
+		words, err := loadStopWordsFile(path)
+		if err != nil {
+			return nil, err
+		}
+		for word := range words {
+			sw[word] = struct{}{}
+		}
+	}
+
+	return sw, nil
+}
+
+func loadStopWordsFile(path string) (StopWords, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open stop words file, %w", err)
+	}
+	defer f.Close()
+
+	sw, err := LoadStopWords(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return sw, nil
+}
@@ -0,0 +1,55 @@
+package hw03_frequency_analysis //nolint:golint
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadStopWords(t *testing.T) {
+	t.Run("reader", func(t *testing.T) {
+		sw, err := LoadStopWords(strings.NewReader("# comment\nthe\n\n  and \r\nthe\n"))
+		require.NoError(t, err)
+		require.Equal(t, StopWords{"the": {}, "and": {}}, sw)
+		require.True(t, sw.Contains("and"))
+		require.False(t, sw.Contains("# comment"))
+	})
+
+	t.Run("read error", func(t *testing.T) {
+		errRead := errors.New("read failed")
+		_, err := LoadStopWords(errReader{errRead})
+		require.True(t, errors.Is(err, errRead))
+	})
+
+	t.Run("files", func(t *testing.T) {
+		sw, err := LoadStopWordsFile("stopwords/russian.txt", "stopwords/english.txt")
+		require.NoError(t, err)
+		require.True(t, sw.Contains("он"))
+		require.True(t, sw.Contains("the"))
+		require.False(t, sw.Contains("кристофер"))
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := LoadStopWordsFile("stopwords/klingon.txt")
+		require.True(t, errors.Is(err, os.ErrNotExist), "unexpected error %v", err)
+	})
+
+	t.Run("nil set", func(t *testing.T) {
+		var sw StopWords
+		require.False(t, sw.Contains("the"))
+	})
+}
+
+func TestTopNWithoutStopWords(t *testing.T) {
+	sw, err := LoadStopWordsFile("stopwords/russian.txt")
+	require.NoError(t, err)
+
+	opts := AdvancedOptions
+	opts.StopWords = sw
+
+	expected := []string{"кристофер", "робин", "винни-пух", "имя", "иногда", "теперь"}
+	require.Equal(t, expected, TopN(text, 6, opts))
+}
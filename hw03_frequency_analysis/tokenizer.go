@@ -0,0 +1,71 @@
+package hw03_frequency_analysis //nolint:golint,stylecheck
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/rivo/uniseg"
+)
+
+// Tokenizer splits text into tokens, Counter splits stream at spaces, so tokens must not contain them.
+type Tokenizer interface {
+	// Tokens passes tokens of text to emit in order.
+	Tokens(text string, emit func(token string))
+}
+
+// WhitespaceTokenizer splits text by space runes, punctuation stays inside tokens.
+type WhitespaceTokenizer struct{}
+
+// Tokens implements Tokenizer.
+func (WhitespaceTokenizer) Tokens(text string, emit func(token string)) {
+	for _, field := range strings.Fields(text) {
+		emit(field)
+	}
+}
+
+// WordBoundaryTokenizer splits text at Unicode word boundaries, segments without letters and digits
+// such as spaces and punctuation are skipped, "can't" and "3.14" are single tokens.
+// @see https://unicode.org/reports/tr29/#Word_Boundaries
+type WordBoundaryTokenizer struct{}
+
+// Tokens implements Tokenizer.
+func (WordBoundaryTokenizer) Tokens(text string, emit func(token string)) {
+	state := -1
+	for text != "" {
+		var word string
+		word, text, state = uniseg.FirstWordInString(text, state)
+		if strings.IndexFunc(word, isWordRune) >= 0 {
+			emit(word)
+		}
+	}
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsNumber(r)
+}
+
+// RegexpTokenizer emits all matches of regular expression.
+type RegexpTokenizer struct {
+	re *regexp.Regexp
+}
+
+// NewRegexpTokenizer creates tokenizer which emits matches of pattern, e.g. `\p{L}+`.
+func NewRegexpTokenizer(pattern string) (*RegexpTokenizer, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid token pattern %q, %w", pattern, err)
+	}
+
+	return &RegexpTokenizer{re}, nil
+}
+
+// Tokens implements Tokenizer.
+func (t *RegexpTokenizer) Tokens(text string, emit func(token string)) {
+	for _, loc := range t.re.FindAllStringIndex(text, -1) {
+		if loc[0] < loc[1] {
+			emit(text[loc[0]:loc[1]])
+		}
+	}
+}
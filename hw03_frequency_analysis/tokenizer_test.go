@@ -0,0 +1,72 @@
+package hw03_frequency_analysis //nolint:golint
+
+import (
+	"errors"
+	"regexp/syntax"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func tokens(tokenizer Tokenizer, text string) []string {
+	result := []string{}
+	tokenizer.Tokens(text, func(token string) {
+		result = append(result, token)
+	})
+
+	return result
+}
+
+func TestTokenizers(t *testing.T) {
+	letters, err := NewRegexpTokenizer(`\p{L}+`)
+	require.NoError(t, err)
+
+	for _, tt := range [...]struct {
+		name      string
+		tokenizer Tokenizer
+		text      string
+		expected  []string
+	}{
+		{
+			name:      "whitespace",
+			tokenizer: WhitespaceTokenizer{},
+			text:      " Нога,\tкакой-то — can't\n",
+			expected:  []string{"Нога,", "какой-то", "—", "can't"},
+		},
+		{
+			name:      "word boundary",
+			tokenizer: WordBoundaryTokenizer{},
+			text:      `Нога, какой-то — can't "3.14"!`,
+			expected:  []string{"Нога", "какой", "то", "can't", "3.14"},
+		},
+		{
+			name:      "word boundary without words",
+			tokenizer: WordBoundaryTokenizer{},
+			text:      " ... — !",
+			expected:  []string{},
+		},
+		{
+			name:      "regexp",
+			tokenizer: letters,
+			text:      `Нога, какой-то — can't 3.14`,
+			expected:  []string{"Нога", "какой", "то", "can", "t"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, tokens(tt.tokenizer, tt.text))
+		})
+	}
+
+	t.Run("regexp skips empty matches", func(t *testing.T) {
+		digits, err := NewRegexpTokenizer(`\d*`)
+		require.NoError(t, err)
+		require.Equal(t, []string{"12", "3"}, tokens(digits, "a12b3"))
+	})
+
+	t.Run("invalid regexp", func(t *testing.T) {
+		_, err := NewRegexpTokenizer(`\p{L`)
+
+		var se *syntax.Error
+		require.True(t, errors.As(err, &se), "unexpected error %v", err)
+	})
+}
@@ -1,6 +1,198 @@
 package hw03_frequency_analysis //nolint:golint,stylecheck
 
-func Top10(_ string) []string {
-	// Place your code here
-	return nil
+import (
+	"container/heap"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Options describes how text is split into words, zero value counts whitespace separated fields as is.
+type Options struct {
+	// FoldCase counts "Нога" and "нога" as the same word.
+	FoldCase bool
+	// TrimPunctuation removes punctuation from word edges, "нога!", "'нога'" and "нога" are the same word.
+	TrimPunctuation bool
+	// ExcludeDashes skips words consisting of dashes only, like "-" and "—".
+	ExcludeDashes bool
+	// KeepHyphenated keeps "какой-то" as a single word when TrimPunctuation is on,
+	// otherwise hyphenated words are split into parts.
+	KeepHyphenated bool
+	// MaxError turns on approximate counting with memory usage O(1/MaxError) instead of
+	// count of distinct words. Counts may be overestimated by at most MaxError * (count of all words),
+	// so words rarer then that may be missed or reported instead of each other. Zero means exact counting.
+	MaxError float64
+	// Workers is count of goroutines counting words of TopN text, values less then 2 mean sequential counting.
+	// Approximate and n-gram counting are always sequential.
+	Workers int
+	// Tokenizer splits text into words before normalization, WhitespaceTokenizer when nil.
+	Tokenizer Tokenizer
+	// StopWords are skipped after normalization.
+	StopWords StopWords
+	// NGram counts phrases of NGram consecutive words joined by space instead of single words,
+	// stop words are removed before phrases are built. Values less then 2 mean single words.
+	NGram int
+	// MaxTokenLength limits length in bytes of whitespace separated field buffered by Counter
+	// until its end is written, longer fields are skipped. Zero means DefaultMaxTokenLength.
+	MaxTokenLength int
+}
+
+// AdvancedOptions normalizes words the way README asks in the task with asterisk.
+var AdvancedOptions = Options{
+	FoldCase:        true,
+	TrimPunctuation: true,
+	ExcludeDashes:   true,
+	KeepHyphenated:  true,
+}
+
+// WordCount is a word with count of its occurrences.
+type WordCount struct {
+	Word  string
+	Count int
+}
+
+// Top10 returns 10 the most frequent words of text, whitespace separated fields are counted as is.
+func Top10(text string) []string {
+	return TopN(text, 10, Options{})
+}
+
+// TopN returns n the most frequent words of text normalized by opts,
+// words with equal count are ordered lexicographically.
+func TopN(text string, n int, opts Options) []string {
+	var top []WordCount
+	if opts.Workers > 1 && opts.MaxError <= 0 && opts.NGram < 2 {
+		top = parallelTop(text, n, opts)
+	} else {
+		c := NewCounter(opts)
+		_, _ = c.Write([]byte(text))
+		c.Flush()
+		top = c.Top(n)
+	}
+
+	words := make([]string, 0, len(top))
+	for _, wc := range top {
+		words = append(words, wc.Word)
+	}
+
+	return words
+}
+
+// topCounts returns n words with the biggest counts, ties are broken lexicographically.
+// It keeps n best words in heap, so it takes O(len(counts) * log(n)) time.
+func topCounts(counts map[string]int, n int) []WordCount {
+	if n <= 0 {
+		return nil
+	}
+
+	// n may be much more then count of distinct words, e.g. when all words are requested
+	size := n
+	if size > len(counts) {
+		size = len(counts)
+	}
+
+	h := make(worstFirst, 0, size+1)
+	for word, cnt := range counts {
+		wc := WordCount{word, cnt}
+		if len(h) == n && !less(wc, h[0]) {
+			continue
+		}
+
+		heap.Push(&h, wc)
+		if len(h) > n {
+			heap.Pop(&h)
+		}
+	}
+
+	sort.Slice(h, func(i, j int) bool {
+		return less(h[i], h[j])
+	})
+
+	return h
+}
+
+// worstFirst is a heap of words, the root is the last one in top.
+type worstFirst []WordCount
+
+func (h worstFirst) Len() int           { return len(h) }
+func (h worstFirst) Less(i, j int) bool { return less(h[j], h[i]) }
+func (h worstFirst) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *worstFirst) Push(x interface{}) {
+	*h = append(*h, x.(WordCount))
+}
+
+func (h *worstFirst) Pop() interface{} {
+	old := *h
+	last := old[len(old)-1]
+	*h = old[:len(old)-1]
+
+	return last
+}
+
+// less returns true when a goes before b in top, i.e. it is more frequent or lexicographically less.
+func less(a, b WordCount) bool {
+	if a.Count != b.Count {
+		return a.Count > b.Count
+	}
+
+	return a.Word < b.Word
+}
+
+// words splits text into tokens and passes normalized ones to emit, stop words are skipped.
+func (o Options) words(text string, emit func(word string)) {
+	tokenizer := o.Tokenizer
+	if tokenizer == nil {
+		tokenizer = WhitespaceTokenizer{}
+	}
+
+	tokenizer.Tokens(text, func(token string) {
+		o.normalize(token, func(word string) {
+			if !o.StopWords.Contains(word) {
+				emit(word)
+			}
+		})
+	})
+}
+
+// normalize converts token into zero or more words and passes them to emit.
+func (o Options) normalize(token string, emit func(word string)) {
+	if isDashes(token) {
+		if !o.ExcludeDashes {
+			emit(token)
+		}
+
+		return
+	}
+
+	if o.FoldCase {
+		token = strings.ToLower(token)
+	}
+
+	if !o.TrimPunctuation {
+		emit(token)
+
+		return
+	}
+
+	parts := []string{token}
+	if !o.KeepHyphenated {
+		parts = strings.FieldsFunc(token, isDash)
+	}
+
+	for _, part := range parts {
+		if word := strings.TrimFunc(part, unicode.IsPunct); word != "" {
+			emit(word)
+		}
+	}
+}
+
+// isDash returns true for hyphen and dashes.
+// @see https://www.fileformat.info/info/unicode/category/Pd/list.htm
+func isDash(r rune) bool {
+	return unicode.In(r, unicode.Pd)
+}
+
+// isDashes returns true when word consists of dashes only.
+func isDashes(word string) bool {
+	return word != "" && strings.IndexFunc(word, func(r rune) bool { return !isDash(r) }) < 0
 }
@@ -1,6 +1,7 @@
 package hw03_frequency_analysis //nolint:golint
 
 import (
+	"math"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -58,3 +59,131 @@ func TestTop10(t *testing.T) {
 		}
 	})
 }
+
+func TestTopN(t *testing.T) {
+	t.Run("advanced options", func(t *testing.T) {
+		expected := []string{"он", "а", "и", "что", "ты", "не", "если", "то", "его", "кристофер", "робин", "в"}
+		result := TopN(text, 10, AdvancedOptions)
+		require.Len(t, result, 10)
+		require.Subset(t, expected, result)
+	})
+
+	for _, tt := range [...]struct {
+		name     string
+		text     string
+		n        int
+		opts     Options
+		expected []string
+	}{
+		{
+			name:     "ties are ordered lexicographically",
+			text:     "b a c b a c d",
+			n:        3,
+			expected: []string{"a", "b", "c"},
+		},
+		{
+			name:     "n more then count of words",
+			text:     "dog cat dog",
+			n:        10,
+			expected: []string{"dog", "cat"},
+		},
+		{
+			name:     "huge n",
+			text:     "dog cat dog",
+			n:        math.MaxInt,
+			expected: []string{"dog", "cat"},
+		},
+		{
+			name:     "huge n in parallel",
+			text:     "dog cat dog",
+			n:        math.MaxInt,
+			opts:     Options{Workers: 4},
+			expected: []string{"dog", "cat"},
+		},
+		{
+			name:     "zero n",
+			text:     "dog cat dog",
+			expected: []string{},
+		},
+		{
+			name:     "fields are counted as is",
+			text:     "Нога нога, нога - — какой-то",
+			n:        10,
+			expected: []string{"-", "Нога", "какой-то", "нога", "нога,", "—"},
+		},
+		{
+			name:     "fold case",
+			text:     "Нога нога НОГА рука",
+			n:        10,
+			opts:     Options{FoldCase: true},
+			expected: []string{"нога", "рука"},
+		},
+		{
+			name:     "trim punctuation",
+			text:     `нога! 'нога' "нога", ...нога… - —`,
+			n:        10,
+			opts:     Options{TrimPunctuation: true, KeepHyphenated: true},
+			expected: []string{"нога", "-", "—"},
+		},
+		{
+			name:     "exclude dashes",
+			text:     "a - b — c -- d",
+			n:        10,
+			opts:     Options{ExcludeDashes: true},
+			expected: []string{"a", "b", "c", "d"},
+		},
+		{
+			name:     "keep hyphenated",
+			text:     "какой-то какойто бум-бум-бум,",
+			n:        10,
+			opts:     Options{TrimPunctuation: true, KeepHyphenated: true},
+			expected: []string{"бум-бум-бум", "какой-то", "какойто"},
+		},
+		{
+			name:     "split hyphenated",
+			text:     "какой-то какойто бум-бум-бум,",
+			n:        10,
+			opts:     Options{TrimPunctuation: true},
+			expected: []string{"бум", "какой", "какойто", "то"},
+		},
+		{
+			name:     "word boundary tokenizer",
+			text:     "Cat, cat's (cat) cat—dog 3.14",
+			n:        10,
+			opts:     Options{Tokenizer: WordBoundaryTokenizer{}},
+			expected: []string{"cat", "3.14", "Cat", "cat's", "dog"},
+		},
+		{
+			name:     "stop words",
+			text:     "the cat and the dog and the cat",
+			n:        10,
+			opts:     Options{StopWords: StopWords{"the": {}, "and": {}}},
+			expected: []string{"cat", "dog"},
+		},
+		{
+			name:     "bigrams",
+			text:     "big cat big cat big dog",
+			n:        10,
+			opts:     Options{NGram: 2},
+			expected: []string{"big cat", "cat big", "big dog"},
+		},
+		{
+			name:     "trigrams without stop words",
+			text:     "Big cat, the big dog. A big cat the big dog!",
+			n:        1,
+			opts:     Options{FoldCase: true, TrimPunctuation: true, NGram: 3, StopWords: StopWords{"the": {}, "a": {}}},
+			expected: []string{"big cat big"},
+		},
+		{
+			name:     "text shorter then n-gram",
+			text:     "big cat",
+			n:        10,
+			opts:     Options{NGram: 3},
+			expected: []string{},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, TopN(tt.text, tt.n, tt.opts))
+		})
+	}
+}
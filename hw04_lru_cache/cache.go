@@ -2,80 +2,375 @@ package hw04_lru_cache //nolint:golint,stylecheck
 
 import (
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 type Key string
 
 type Cache interface {
-	Set(key Key, value interface{}) bool // Добавить значение в кэш по ключу
-	Get(key Key) (interface{}, bool)     // Получить значение из кэша по ключу
-	Clear()                              // Очистить кэш
+	Set(key Key, value interface{}) bool                           // Добавить значение в кэш по ключу
+	SetWithTTL(key Key, value interface{}, ttl time.Duration) bool // Добавить значение, которое устареет через ttl
+	Get(key Key) (interface{}, bool)                               // Получить значение из кэша по ключу
+	Remove(key Key) bool                                           // Удалить значение из кэша по ключу
+	Clear()                                                        // Очистить кэш
+	Close()                                                        // Остановить фоновую очистку
+	OnEvict(fn EvictFunc)                                          // Подписаться на удаление значений
+	Stats() Stats                                                  // Получить статистику использования
+}
+
+// Stats describes cache usage since creation.
+type Stats struct {
+	Hits     uint64 // Get found value
+	Misses   uint64 // Get didn't find value or found expired one
+	Sets     uint64 // Set added new value
+	Updates  uint64 // Set replaced value already in cache
+	Evicted  uint64 // values pushed out by capacity
+	Expired  uint64 // expired values removed
+	Removed  uint64 // values removed by Remove
+	Cleared  uint64 // values removed by Clear
+	Size     int    // count of values in cache including expired ones not removed yet
+	Capacity int
+}
+
+// HitRatio returns share of Get calls which found value, 0 when there were no calls.
+func (s Stats) HitRatio() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// EvictReason describes why value left cache.
+type EvictReason int
+
+const (
+	// EvictCapacity happens when the least recently used value is pushed out by new one.
+	EvictCapacity EvictReason = iota
+	// EvictExpired happens when expired value is found by Get, Set or janitor.
+	EvictExpired
+	// EvictRemoved happens on Remove.
+	EvictRemoved
+	// EvictCleared happens on Clear.
+	EvictCleared
+)
+
+var evictReasons = [...]string{
+	EvictCapacity: "capacity",
+	EvictExpired:  "expired",
+	EvictRemoved:  "removed",
+	EvictCleared:  "cleared",
+}
+
+func (r EvictReason) String() string {
+	if r < 0 || int(r) >= len(evictReasons) {
+		return "unknown"
+	}
+
+	return evictReasons[r]
+}
+
+// EvictFunc is called when value leaves cache, it is called without cache lock held,
+// so it may use cache and do slow work like closing resources held by value.
+type EvictFunc func(key Key, value interface{}, reason EvictReason)
+
+// eviction is a value left cache, handlers are called with it after cache is unlocked.
+type eviction struct {
+	key    Key
+	value  interface{}
+	reason EvictReason
+}
+
+// Options describes expiration of cache entries.
+type Options struct {
+	DefaultTTL      time.Duration    // TTL of entries stored by Set, 0 means entries don't expire, negative ones aren't stored
+	CleanupInterval time.Duration    // period of background removal of expired entries, 0 means no janitor
+	Now             func() time.Time // clock of cache, time.Now by default
 }
 
 type cacheItem struct {
-	key   Key
-	value interface{}
+	key       Key
+	value     interface{}
+	expiresAt time.Time // zero means item never expires
+}
+
+// expired returns true when item is stale at now.
+func (ci *cacheItem) expired(now time.Time) bool {
+	return !ci.expiresAt.IsZero() && !now.Before(ci.expiresAt)
 }
 
 type lruCache struct {
+	// counters are updated atomically, so Stats doesn't wait for lock,
+	// they go first to be 64-bit aligned on 32-bit platforms
+	hits, misses, sets, updates uint64
+	evictions                   [len(evictReasons)]uint64
+
 	capacity int
+	opts     Options
 	queue    List
 	items    map[Key]*Item
 	mu       sync.Mutex
+	onEvict  []EvictFunc
+
+	janitor janitor
 }
 
-// NewCache creates new Cache instance.
+// NewCache creates new Cache instance, entries don't expire.
+// Cache with capacity less then 1 stores nothing.
 func NewCache(capacity int) Cache {
+	return NewCacheWithOptions(capacity, Options{})
+}
+
+// NewCacheWithOptions creates new Cache instance with expiration described by opts,
+// when CleanupInterval is set Close must be called to stop background goroutine.
+func NewCacheWithOptions(capacity int, opts Options) Cache {
+	c := newLRUCache(capacity, opts)
+	c.janitor.start(opts.CleanupInterval, c.removeExpired)
+
+	return c
+}
+
+// newLRUCache creates cache without janitor.
+func newLRUCache(capacity int, opts Options) *lruCache {
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	if capacity < 0 {
+		capacity = 0
+	}
+
 	return &lruCache{
 		capacity: capacity,
+		opts:     opts,
 		queue:    NewList(),
 		items:    make(map[Key]*Item, capacity),
 	}
 }
 
 // Get returns {value, true} whether element with key stored in cache and {nil, false} pair otherwise.
+// Expired elements aren't returned.
 func (c *lruCache) Get(key Key) (interface{}, bool) {
 	c.mu.Lock()
-	defer c.mu.Unlock()
 
-	if item, ok := c.items[key]; ok {
-		c.queue.MoveToFront(item)
+	item, ok := c.items[key]
+	if !ok {
+		c.mu.Unlock()
+		atomic.AddUint64(&c.misses, 1)
 
-		return item.Value.(*cacheItem).value, true
+		return nil, false
 	}
 
-	return nil, false
+	ci := item.Value.(*cacheItem)
+	if ci.expired(c.opts.Now()) {
+		evicted := c.remove(item, EvictExpired, nil)
+		c.mu.Unlock()
+		atomic.AddUint64(&c.misses, 1)
+		c.notify(evicted)
+
+		return nil, false
+	}
+
+	c.queue.MoveToFront(item)
+	value := ci.value
+	c.mu.Unlock()
+	atomic.AddUint64(&c.hits, 1)
+
+	return value, true
 }
 
-// Set stores value in cache with key, returns whether element was already in cache.
+// Set stores value in cache with key and default TTL, returns whether element was already in cache.
 func (c *lruCache) Set(key Key, value interface{}) bool {
+	return c.SetWithTTL(key, value, c.opts.DefaultTTL)
+}
+
+// SetWithTTL stores value in cache with key, value expires after ttl, zero ttl means it never expires.
+// Negative ttl means value is already expired, so it isn't stored and previous value of key is removed.
+// It returns whether not expired element was already in cache.
+func (c *lruCache) SetWithTTL(key Key, value interface{}, ttl time.Duration) bool {
+	// value doesn't fit in cache of zero capacity, so it is dropped as if it expired immediately
+	if ttl < 0 || c.capacity == 0 {
+		return c.Remove(key)
+	}
+
 	c.mu.Lock()
-	defer c.mu.Unlock()
+
+	now := c.opts.Now()
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = now.Add(ttl)
+	}
 
 	if item, ok := c.items[key]; ok {
 		c.queue.MoveToFront(item)
-		item.Value.(*cacheItem).value = value
+		ci := item.Value.(*cacheItem)
+		wasInCache := !ci.expired(now)
+
+		// expired value is replaced, so it leaves cache
+		var evicted []eviction
+		if !wasInCache && len(c.onEvict) > 0 {
+			evicted = append(evicted, eviction{key, ci.value, EvictExpired})
+		}
 
-		return true
+		ci.value = value
+		ci.expiresAt = expiresAt
+		c.mu.Unlock()
+
+		if wasInCache {
+			atomic.AddUint64(&c.updates, 1)
+		} else {
+			atomic.AddUint64(&c.sets, 1)
+			atomic.AddUint64(&c.evictions[EvictExpired], 1)
+		}
+		c.notify(evicted)
+
+		return wasInCache
 	}
 
+	var evicted []eviction
 	if c.capacity == c.queue.Len() {
-		lastItem := c.queue.Back()
-		c.queue.Remove(lastItem)
-		delete(c.items, lastItem.Value.(*cacheItem).key)
+		back := c.queue.Back()
+		reason := EvictCapacity
+		if back.Value.(*cacheItem).expired(now) {
+			reason = EvictExpired
+		}
+		evicted = c.remove(back, reason, evicted)
 	}
 
-	c.queue.PushFront(&cacheItem{key, value})
+	c.queue.PushFront(&cacheItem{key, value, expiresAt})
 	c.items[key] = c.queue.Front()
+	c.mu.Unlock()
+	atomic.AddUint64(&c.sets, 1)
+
+	c.notify(evicted)
 
 	return false
 }
 
+// Remove deletes element with key from cache, returns whether not expired element was in cache.
+func (c *lruCache) Remove(key Key) bool {
+	c.mu.Lock()
+
+	item, ok := c.items[key]
+	if !ok {
+		c.mu.Unlock()
+
+		return false
+	}
+
+	reason := EvictRemoved
+	if item.Value.(*cacheItem).expired(c.opts.Now()) {
+		reason = EvictExpired
+	}
+	evicted := c.remove(item, reason, nil)
+	c.mu.Unlock()
+
+	c.notify(evicted)
+
+	return reason == EvictRemoved
+}
+
 // Clear removes all elements from cache.
 func (c *lruCache) Clear() {
 	c.mu.Lock()
-	defer c.mu.Unlock()
 
+	var evicted []eviction
+	if len(c.onEvict) > 0 {
+		evicted = make([]eviction, 0, c.queue.Len())
+		for item := c.queue.Back(); item != nil; item = item.Prev {
+			ci := item.Value.(*cacheItem)
+			evicted = append(evicted, eviction{ci.key, ci.value, EvictCleared})
+		}
+	}
+
+	atomic.AddUint64(&c.evictions[EvictCleared], uint64(c.queue.Len()))
 	c.queue = NewList()
 	c.items = make(map[Key]*Item, c.capacity)
+	c.mu.Unlock()
+
+	c.notify(evicted)
+}
+
+// OnEvict adds fn to handlers called when value leaves cache, handlers are called in order of addition.
+// Values removed by one call of cache method are passed in order from the least recently used.
+func (c *lruCache) OnEvict(fn EvictFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.onEvict = append(c.onEvict, fn)
+}
+
+// Close stops background removal of expired elements, cache is still usable after Close.
+func (c *lruCache) Close() {
+	c.janitor.stop()
+}
+
+// Stats returns counters of cache usage.
+func (c *lruCache) Stats() Stats {
+	c.mu.Lock()
+	size := c.queue.Len()
+	c.mu.Unlock()
+
+	return Stats{
+		Hits:     atomic.LoadUint64(&c.hits),
+		Misses:   atomic.LoadUint64(&c.misses),
+		Sets:     atomic.LoadUint64(&c.sets),
+		Updates:  atomic.LoadUint64(&c.updates),
+		Evicted:  atomic.LoadUint64(&c.evictions[EvictCapacity]),
+		Expired:  atomic.LoadUint64(&c.evictions[EvictExpired]),
+		Removed:  atomic.LoadUint64(&c.evictions[EvictRemoved]),
+		Cleared:  atomic.LoadUint64(&c.evictions[EvictCleared]),
+		Size:     size,
+		Capacity: c.capacity,
+	}
+}
+
+// removeExpired removes all expired elements, it takes O(len(items)) time.
+func (c *lruCache) removeExpired() {
+	c.mu.Lock()
+
+	var evicted []eviction
+	now := c.opts.Now()
+	for item := c.queue.Back(); item != nil; {
+		prev := item.Prev
+		if item.Value.(*cacheItem).expired(now) {
+			evicted = c.remove(item, EvictExpired, evicted)
+		}
+		item = prev
+	}
+	c.mu.Unlock()
+
+	c.notify(evicted)
+}
+
+// remove deletes item from queue and items, c.mu must be held.
+// Removed value is appended to evicted when there are handlers to notify.
+func (c *lruCache) remove(item *Item, reason EvictReason, evicted []eviction) []eviction {
+	c.queue.Remove(item)
+	ci := item.Value.(*cacheItem)
+	delete(c.items, ci.key)
+	atomic.AddUint64(&c.evictions[reason], 1)
+
+	if len(c.onEvict) == 0 {
+		return evicted
+	}
+
+	return append(evicted, eviction{ci.key, ci.value, reason})
+}
+
+// notify calls handlers with evicted values, c.mu must not be held.
+func (c *lruCache) notify(evicted []eviction) {
+	if len(evicted) == 0 {
+		return
+	}
+
+	c.mu.Lock()
+	handlers := c.onEvict
+	c.mu.Unlock()
+
+	for _, e := range evicted {
+		for _, fn := range handlers {
+			fn(e.key, e.value, e.reason)
+		}
+	}
 }
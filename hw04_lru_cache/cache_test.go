@@ -5,6 +5,7 @@ import (
 	"strconv"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -147,6 +148,18 @@ func TestCache(t *testing.T) {
 	})
 }
 
+func TestCacheZeroCapacity(t *testing.T) {
+	for _, capacity := range [...]int{0, -1} {
+		c := NewCache(capacity)
+
+		require.False(t, c.Set("aaa", 100))
+		require.False(t, c.Set("aaa", 200))
+		_, ok := c.Get("aaa")
+		require.False(t, ok)
+		require.Equal(t, Stats{Misses: 1}, c.Stats())
+	}
+}
+
 func TestClear(t *testing.T) {
 	t.Run("simple", func(t *testing.T) {
 		c := NewCache(2)
@@ -178,6 +191,295 @@ func TestClear(t *testing.T) {
 	})
 }
 
+// fakeClock is a clock moved by tests.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2020, 9, 18, 0, 0, 0, 0, time.UTC)}
+}
+
+func (fc *fakeClock) Now() time.Time {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	return fc.now
+}
+
+func (fc *fakeClock) Add(d time.Duration) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	fc.now = fc.now.Add(d)
+}
+
+func TestCacheTTL(t *testing.T) {
+	t.Run("set with ttl", func(t *testing.T) {
+		clock := newFakeClock()
+		c := NewCacheWithOptions(10, Options{Now: clock.Now})
+
+		c.SetWithTTL("aaa", 100, time.Minute)
+		c.Set("bbb", 200) // never expires
+
+		clock.Add(59 * time.Second)
+		val, ok := c.Get("aaa")
+		require.True(t, ok)
+		require.Equal(t, 100, val)
+
+		clock.Add(time.Second)
+		val, ok = c.Get("aaa")
+		require.False(t, ok)
+		require.Nil(t, val)
+
+		clock.Add(time.Hour)
+		val, ok = c.Get("bbb")
+		require.True(t, ok)
+		require.Equal(t, 200, val)
+	})
+
+	t.Run("default ttl", func(t *testing.T) {
+		clock := newFakeClock()
+		c := NewCacheWithOptions(10, Options{DefaultTTL: time.Minute, Now: clock.Now})
+
+		c.Set("aaa", 100)
+		c.SetWithTTL("bbb", 200, time.Hour)
+
+		clock.Add(time.Minute)
+		_, ok := c.Get("aaa")
+		require.False(t, ok)
+
+		_, ok = c.Get("bbb")
+		require.True(t, ok)
+	})
+
+	t.Run("set renews expired item", func(t *testing.T) {
+		clock := newFakeClock()
+		c := NewCacheWithOptions(10, Options{Now: clock.Now})
+
+		require.False(t, c.SetWithTTL("aaa", 100, time.Minute))
+		require.True(t, c.SetWithTTL("aaa", 150, time.Minute))
+
+		clock.Add(time.Minute)
+		wasInCache := c.SetWithTTL("aaa", 200, time.Minute)
+		require.False(t, wasInCache, "expired item isn't in cache")
+
+		clock.Add(30 * time.Second)
+		val, ok := c.Get("aaa")
+		require.True(t, ok)
+		require.Equal(t, 200, val)
+	})
+
+	t.Run("negative ttl", func(t *testing.T) {
+		c := NewCache(10)
+
+		require.False(t, c.SetWithTTL("aaa", 100, -time.Second))
+		_, ok := c.Get("aaa")
+		require.False(t, ok)
+
+		c.Set("bbb", 200)
+		require.True(t, c.SetWithTTL("bbb", 300, -time.Second), "previous value was in cache")
+		_, ok = c.Get("bbb")
+		require.False(t, ok)
+		require.Zero(t, c.Stats().Size)
+	})
+
+	t.Run("negative default ttl", func(t *testing.T) {
+		c := NewCacheWithOptions(10, Options{DefaultTTL: -time.Second})
+
+		c.Set("aaa", 100)
+		_, ok := c.Get("aaa")
+		require.False(t, ok)
+	})
+
+	t.Run("remove expired", func(t *testing.T) {
+		clock := newFakeClock()
+		c := NewCacheWithOptions(10, Options{Now: clock.Now}).(*lruCache)
+
+		c.SetWithTTL("aaa", 100, time.Minute)
+		c.SetWithTTL("bbb", 200, time.Hour)
+		c.SetWithTTL("ccc", 300, time.Minute)
+
+		clock.Add(time.Minute)
+		c.removeExpired()
+		require.Equal(t, 1, c.queue.Len())
+		require.Len(t, c.items, 1)
+
+		_, ok := c.Get("bbb")
+		require.True(t, ok)
+	})
+
+	t.Run("janitor", func(t *testing.T) {
+		clock := newFakeClock()
+		c := NewCacheWithOptions(10, Options{CleanupInterval: time.Millisecond, Now: clock.Now}).(*lruCache)
+		defer c.Close()
+
+		c.SetWithTTL("aaa", 100, time.Minute)
+		clock.Add(time.Minute)
+
+		require.Eventually(t, func() bool {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+
+			return c.queue.Len() == 0
+		}, time.Second, time.Millisecond)
+	})
+
+	t.Run("close", func(t *testing.T) {
+		c := NewCacheWithOptions(10, Options{CleanupInterval: time.Millisecond})
+		c.Close()
+		c.Close()
+
+		c.Set("aaa", 100)
+		val, ok := c.Get("aaa")
+		require.True(t, ok)
+		require.Equal(t, 100, val)
+	})
+}
+
+type evicted struct {
+	key    Key
+	value  interface{}
+	reason EvictReason
+}
+
+func TestCacheOnEvict(t *testing.T) {
+	clock := newFakeClock()
+	c := NewCacheWithOptions(3, Options{Now: clock.Now})
+
+	var events []evicted
+	c.OnEvict(func(key Key, value interface{}, reason EvictReason) {
+		events = append(events, evicted{key, value, reason})
+	})
+
+	c.Set("aaa", 100)
+	c.Set("bbb", 200)
+	c.Set("ccc", 300)
+	c.Set("aaa", 150) // update isn't eviction, [aaa, ccc, bbb]
+	require.Empty(t, events)
+
+	c.Set("ddd", 400) // [ddd, aaa, ccc]
+	require.Equal(t, []evicted{{"bbb", 200, EvictCapacity}}, events)
+
+	require.True(t, c.Remove("ccc")) // [ddd, aaa]
+	require.False(t, c.Remove("ccc"))
+	require.Equal(t, evicted{"ccc", 300, EvictRemoved}, events[1])
+
+	c.SetWithTTL("eee", 500, time.Minute) // [eee, ddd, aaa]
+	clock.Add(time.Minute)
+	_, ok := c.Get("eee")
+	require.False(t, ok)
+	require.Equal(t, evicted{"eee", 500, EvictExpired}, events[2])
+
+	c.Clear()
+	require.Equal(t, []evicted{{"aaa", 150, EvictCleared}, {"ddd", 400, EvictCleared}}, events[3:])
+
+	t.Run("expired element pushed out by capacity", func(t *testing.T) {
+		c := NewCacheWithOptions(1, Options{Now: clock.Now})
+
+		var reasons []EvictReason
+		c.OnEvict(func(_ Key, _ interface{}, reason EvictReason) {
+			reasons = append(reasons, reason)
+		})
+
+		c.SetWithTTL("aaa", 100, time.Minute)
+		clock.Add(time.Minute)
+		c.Set("bbb", 200)
+		require.Equal(t, []EvictReason{EvictExpired}, reasons)
+	})
+
+	t.Run("expired element replaced by set", func(t *testing.T) {
+		c := NewCacheWithOptions(10, Options{Now: clock.Now})
+
+		var events []evicted
+		c.OnEvict(func(key Key, value interface{}, reason EvictReason) {
+			events = append(events, evicted{key, value, reason})
+		})
+
+		c.SetWithTTL("aaa", 100, time.Minute)
+		clock.Add(time.Minute)
+		c.Set("aaa", 200)
+		require.Equal(t, []evicted{{"aaa", 100, EvictExpired}}, events)
+	})
+
+	t.Run("janitor", func(t *testing.T) {
+		c := NewCacheWithOptions(10, Options{Now: clock.Now}).(*lruCache)
+
+		var keys []Key
+		c.OnEvict(func(key Key, _ interface{}, reason EvictReason) {
+			require.Equal(t, EvictExpired, reason)
+			keys = append(keys, key)
+		})
+
+		c.SetWithTTL("aaa", 100, time.Minute)
+		c.SetWithTTL("bbb", 200, time.Hour)
+		c.SetWithTTL("ccc", 300, time.Minute)
+		clock.Add(time.Minute)
+		c.removeExpired()
+		require.Equal(t, []Key{"aaa", "ccc"}, keys)
+	})
+
+	t.Run("handler uses cache", func(t *testing.T) {
+		c := NewCache(1)
+		archive := NewCache(10)
+		c.OnEvict(func(key Key, value interface{}, _ EvictReason) {
+			// callback runs without lock, so it doesn't deadlock
+			_, _ = c.Get(key)
+			archive.Set(key, value)
+		})
+
+		c.Set("aaa", 100)
+		c.Set("bbb", 200)
+
+		val, ok := archive.Get("aaa")
+		require.True(t, ok)
+		require.Equal(t, 100, val)
+	})
+}
+
+func TestEvictReasonString(t *testing.T) {
+	require.Equal(t, "capacity", EvictCapacity.String())
+	require.Equal(t, "cleared", EvictCleared.String())
+	require.Equal(t, "unknown", EvictReason(42).String())
+}
+
+func TestCacheStats(t *testing.T) {
+	clock := newFakeClock()
+	c := NewCacheWithOptions(2, Options{Now: clock.Now})
+	require.Equal(t, Stats{Capacity: 2}, c.Stats())
+	require.Zero(t, c.Stats().HitRatio())
+
+	c.Set("aaa", 100)                     // set
+	c.Set("aaa", 150)                     // update
+	c.SetWithTTL("bbb", 200, time.Minute) // set
+	c.Get("aaa")                          // hit
+	c.Get("ccc")                          // miss
+	c.Set("ccc", 300)                     // set, bbb evicted
+	c.Get("bbb")                          // miss
+	c.SetWithTTL("aaa", 100, time.Minute) // update
+	clock.Add(time.Minute)
+	c.Get("aaa")    // miss, expired
+	c.Remove("ccc") // removed
+	c.Set("ddd", 400)
+	c.Clear() // cleared
+
+	stats := c.Stats()
+	require.Equal(t, Stats{
+		Hits:     1,
+		Misses:   3,
+		Sets:     4,
+		Updates:  2,
+		Evicted:  1,
+		Expired:  1,
+		Removed:  1,
+		Cleared:  1,
+		Size:     0,
+		Capacity: 2,
+	}, stats)
+	require.Equal(t, 0.25, stats.HitRatio())
+}
+
 func TestCacheMultithreading(t *testing.T) {
 	c := NewCache(10)
 	wg := &sync.WaitGroup{}
@@ -0,0 +1,91 @@
+package generic
+
+import (
+	"sync"
+)
+
+// Cache is a cache of values of type V by keys of type K.
+type Cache[K comparable, V any] interface {
+	Set(key K, value V) bool // Добавить значение в кэш по ключу
+	Get(key K) (V, bool)     // Получить значение из кэша по ключу
+	Clear()                  // Очистить кэш
+}
+
+type cacheItem[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+type lruCache[K comparable, V any] struct {
+	capacity int
+	queue    List[cacheItem[K, V]]
+	items    map[K]*Item[cacheItem[K, V]]
+	mu       sync.Mutex
+}
+
+// NewCache creates new Cache instance.
+// Cache with capacity less then 1 stores nothing.
+func NewCache[K comparable, V any](capacity int) Cache[K, V] {
+	if capacity < 0 {
+		capacity = 0
+	}
+
+	return &lruCache[K, V]{
+		capacity: capacity,
+		queue:    NewList[cacheItem[K, V]](),
+		items:    make(map[K]*Item[cacheItem[K, V]], capacity),
+	}
+}
+
+// Get returns {value, true} whether element with key stored in cache and {zero value, false} pair otherwise.
+func (c *lruCache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if item, ok := c.items[key]; ok {
+		c.queue.MoveToFront(item)
+
+		return item.Value.value, true
+	}
+
+	var zero V
+
+	return zero, false
+}
+
+// Set stores value in cache with key, returns whether element was already in cache.
+func (c *lruCache[K, V]) Set(key K, value V) bool {
+	// value doesn't fit in cache of zero capacity
+	if c.capacity == 0 {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if item, ok := c.items[key]; ok {
+		c.queue.MoveToFront(item)
+		item.Value.value = value
+
+		return true
+	}
+
+	if c.capacity == c.queue.Len() {
+		lastItem := c.queue.Back()
+		c.queue.Remove(lastItem)
+		delete(c.items, lastItem.Value.key)
+	}
+
+	c.items[key] = c.queue.PushFront(cacheItem[K, V]{key, value})
+
+	return false
+}
+
+// Clear removes all elements from cache.
+func (c *lruCache[K, V]) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.queue = NewList[cacheItem[K, V]]()
+	c.items = make(map[K]*Item[cacheItem[K, V]], c.capacity)
+}
@@ -0,0 +1,135 @@
+// Package generic contains type parameterized versions of List and Cache,
+// values are stored without boxing in interface{} and type assertions.
+package generic
+
+// List is a doubly linked list of values of type T.
+type List[T any] interface {
+	Len() int               // длина списка
+	Front() *Item[T]        // первый Item
+	Back() *Item[T]         // последний Item
+	PushFront(v T) *Item[T] // добавить значение в начало
+	PushBack(v T) *Item[T]  // добавить значение в конец
+	Remove(i *Item[T])      // удалить элемент
+	MoveToFront(i *Item[T]) // переместить элемент в начало
+}
+
+// Item is an element of List.
+type Item[T any] struct {
+	Next  *Item[T]
+	Prev  *Item[T]
+	Value T
+}
+
+type list[T any] struct {
+	first *Item[T]
+	last  *Item[T]
+	len   int
+}
+
+// NewList creates new instance of list.
+func NewList[T any]() List[T] {
+	return &list[T]{}
+}
+
+// Len returns count of elements in list.
+func (l list[T]) Len() int {
+	return l.len
+}
+
+// Front returns the first element from list.
+func (l list[T]) Front() *Item[T] {
+	return l.first
+}
+
+// Back returns the last element from list.
+func (l list[T]) Back() *Item[T] {
+	return l.last
+}
+
+// PushFront add new element at the begin of list.
+func (l *list[T]) PushFront(v T) *Item[T] {
+	i := &Item[T]{
+		Next:  l.first,
+		Value: v,
+	}
+
+	if l.first == nil {
+		l.last = i
+	} else {
+		l.first.Prev = i
+	}
+
+	l.first = i
+	l.len++
+
+	return i
+}
+
+// PushBack add new element at the end of list.
+func (l *list[T]) PushBack(v T) *Item[T] {
+	i := &Item[T]{
+		Prev:  l.last,
+		Value: v,
+	}
+
+	if l.last == nil {
+		l.first = i
+	} else {
+		l.last.Next = i
+	}
+
+	l.last = i
+	l.len++
+
+	return i
+}
+
+// Remove delete element i from list.
+func (l *list[T]) Remove(i *Item[T]) {
+	if l.len < 2 {
+		l.first = nil
+		l.last = nil
+		l.len = 0
+
+		return
+	}
+
+	if i.Prev != nil {
+		i.Prev.Next = i.Next
+	} else {
+		l.first = i.Next
+		i.Next.Prev = nil
+	}
+
+	if i.Next != nil {
+		i.Next.Prev = i.Prev
+	} else {
+		l.last = i.Prev
+		i.Prev.Next = nil
+	}
+
+	l.len--
+}
+
+// MoveToFront move element i from current position of list to begin.
+func (l *list[T]) MoveToFront(i *Item[T]) {
+	// i is the first element, nothing to do
+	if l.len < 2 || i.Prev == nil {
+		return
+	}
+
+	// remove item, bind left and right elements together
+	i.Prev.Next = i.Next
+	if i.Next != nil {
+		i.Next.Prev = i.Prev
+	} else {
+		// remove last element
+		l.last = i.Prev
+	}
+
+	// place element i at begin
+	i.Prev = nil
+	i.Next = l.first
+	l.first.Prev = i
+	l.first = i
+}
@@ -0,0 +1,83 @@
+package generic
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func listToSlice[T any](l List[T]) []T {
+	elems := make([]T, 0, l.Len())
+	for i := l.Front(); i != nil; i = i.Next {
+		elems = append(elems, i.Value)
+	}
+
+	return elems
+}
+
+func TestList(t *testing.T) {
+	t.Run("empty list", func(t *testing.T) {
+		l := NewList[int]()
+
+		require.Equal(t, 0, l.Len())
+		require.Nil(t, l.Front())
+		require.Nil(t, l.Back())
+	})
+
+	t.Run("complex", func(t *testing.T) {
+		l := NewList[int]()
+
+		l.PushFront(10) // [10]
+		l.PushBack(20)  // [10, 20]
+		l.PushBack(30)  // [10, 20, 30]
+		require.Equal(t, 3, l.Len())
+
+		middle := l.Front().Next // 20
+		l.Remove(middle)         // [10, 30]
+		require.Equal(t, 2, l.Len())
+
+		for i, v := range [...]int{40, 50, 60, 70, 80} {
+			if i%2 == 0 {
+				l.PushFront(v)
+			} else {
+				l.PushBack(v)
+			}
+		} // [80, 60, 40, 10, 30, 50, 70]
+
+		require.Equal(t, 7, l.Len())
+		require.Equal(t, 80, l.Front().Value)
+		require.Equal(t, 70, l.Back().Value)
+
+		l.MoveToFront(l.Front()) // [80, 60, 40, 10, 30, 50, 70]
+		l.MoveToFront(l.Back())  // [70, 80, 60, 40, 10, 30, 50]
+		require.Equal(t, []int{70, 80, 60, 40, 10, 30, 50}, listToSlice(l))
+	})
+
+	t.Run("remove front and back", func(t *testing.T) {
+		l := NewList[string]()
+		for _, v := range [...]string{"a", "b", "c"} {
+			l.PushBack(v)
+		}
+
+		l.Remove(l.Front())
+		require.Equal(t, []string{"b", "c"}, listToSlice(l))
+		l.Remove(l.Back())
+		require.Equal(t, []string{"b"}, listToSlice(l))
+		l.Remove(l.Back())
+		require.Equal(t, 0, l.Len())
+		require.Nil(t, l.Front())
+		require.Nil(t, l.Back())
+	})
+
+	t.Run("move middle to front", func(t *testing.T) {
+		l := NewList[int]()
+		for v := 1; v <= 3; v++ {
+			l.PushBack(v)
+		}
+
+		l.MoveToFront(l.Front().Next)
+		require.Equal(t, []int{2, 1, 3}, listToSlice(l))
+		require.Equal(t, 3, l.Back().Value)
+		require.Nil(t, l.Front().Prev)
+	})
+}
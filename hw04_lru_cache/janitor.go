@@ -0,0 +1,55 @@
+package hw04_lru_cache //nolint:golint,stylecheck
+
+import (
+	"sync"
+	"time"
+)
+
+// janitor calls cleanup function periodically in background goroutine until stop.
+// Zero value is ready to use.
+type janitor struct {
+	mu      sync.Mutex
+	done    chan struct{}
+	wg      sync.WaitGroup
+	stopped bool
+}
+
+// start runs cleanup every interval, nothing is started when interval isn't positive.
+func (j *janitor) start(interval time.Duration, cleanup func()) {
+	if interval <= 0 {
+		return
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.done = make(chan struct{})
+	j.wg.Add(1)
+	go func() {
+		defer j.wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-j.done:
+				return
+			case <-ticker.C:
+				cleanup()
+			}
+		}
+	}()
+}
+
+// stop stops background goroutine and waits for it, it may be called several times.
+func (j *janitor) stop() {
+	j.mu.Lock()
+	if j.done != nil && !j.stopped {
+		close(j.done)
+		j.stopped = true
+	}
+	j.mu.Unlock()
+
+	j.wg.Wait()
+}
@@ -0,0 +1,87 @@
+package hw04_lru_cache //nolint:golint,stylecheck
+
+import (
+	"expvar"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+)
+
+// WriteMetrics writes stats of cache with name in Prometheus text exposition format.
+func WriteMetrics(w io.Writer, name string, s Stats) (int64, error) {
+	pw := &promWriter{w: w}
+	labels := fmt.Sprintf("{cache=%q}", name)
+
+	for _, metric := range [...]struct {
+		name, kind, help string
+		value            float64
+	}{
+		{"lru_cache_hits_total", "counter", "Count of Get calls which found value.", float64(s.Hits)},
+		{"lru_cache_misses_total", "counter", "Count of Get calls which didn't find value.", float64(s.Misses)},
+		{"lru_cache_sets_total", "counter", "Count of Set calls which added new value.", float64(s.Sets)},
+		{"lru_cache_updates_total", "counter", "Count of Set calls which replaced value.", float64(s.Updates)},
+		{"lru_cache_size", "gauge", "Count of values in cache.", float64(s.Size)},
+		{"lru_cache_capacity", "gauge", "Max count of values in cache.", float64(s.Capacity)},
+	} {
+		pw.header(metric.name, metric.kind, metric.help)
+		pw.sample(metric.name, labels, metric.value)
+	}
+
+	pw.header("lru_cache_evictions_total", "counter", "Count of values left cache by reason.")
+	for _, e := range [...]struct {
+		reason EvictReason
+		value  uint64
+	}{
+		{EvictCapacity, s.Evicted},
+		{EvictExpired, s.Expired},
+		{EvictRemoved, s.Removed},
+		{EvictCleared, s.Cleared},
+	} {
+		pw.sample("lru_cache_evictions_total", fmt.Sprintf("{cache=%q,reason=%q}", name, e.reason), float64(e.value))
+	}
+
+	return pw.n, pw.err
+}
+
+// MetricsHandler serves stats of cache with name in Prometheus text exposition format.
+func MetricsHandler(name string, c Cache) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		if _, err := WriteMetrics(w, name, c.Stats()); err != nil {
+			log.Printf("unable to write metrics, %s", err)
+		}
+	})
+}
+
+// PublishExpvar publishes stats of cache as expvar variable with name, it panics when name is already used.
+func PublishExpvar(name string, c Cache) {
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		return c.Stats()
+	}))
+}
+
+// promWriter writes Prometheus text lines and keeps the first error.
+type promWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (pw *promWriter) printf(format string, args ...interface{}) {
+	if pw.err != nil {
+		return
+	}
+
+	n, err := fmt.Fprintf(pw.w, format, args...)
+	pw.n += int64(n)
+	pw.err = err
+}
+
+func (pw *promWriter) header(name, kind, help string) {
+	pw.printf("# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+func (pw *promWriter) sample(name, labels string, value float64) {
+	pw.printf("%s%s %g\n", name, labels, value)
+}
@@ -0,0 +1,59 @@
+package hw04_lru_cache //nolint:golint,stylecheck
+
+import (
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteMetrics(t *testing.T) {
+	var sb strings.Builder
+	n, err := WriteMetrics(&sb, "users", Stats{Hits: 3, Misses: 1, Evicted: 2, Size: 5, Capacity: 10})
+	require.NoError(t, err)
+	require.Equal(t, int64(sb.Len()), n)
+
+	out := sb.String()
+	for _, line := range [...]string{
+		"# TYPE lru_cache_hits_total counter\n",
+		`lru_cache_hits_total{cache="users"} 3` + "\n",
+		`lru_cache_misses_total{cache="users"} 1` + "\n",
+		`lru_cache_size{cache="users"} 5` + "\n",
+		`lru_cache_capacity{cache="users"} 10` + "\n",
+		`lru_cache_evictions_total{cache="users",reason="capacity"} 2` + "\n",
+		`lru_cache_evictions_total{cache="users",reason="cleared"} 0` + "\n",
+	} {
+		require.Contains(t, out, line)
+	}
+}
+
+func TestMetricsHandler(t *testing.T) {
+	c := NewCache(10)
+	c.Set("aaa", 100)
+	c.Get("aaa")
+
+	rec := httptest.NewRecorder()
+	MetricsHandler("sessions", c).ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	require.Equal(t, "text/plain; version=0.0.4", rec.Header().Get("Content-Type"))
+	require.Contains(t, rec.Body.String(), `lru_cache_hits_total{cache="sessions"} 1`+"\n")
+}
+
+// expvarRuns makes name of published variable unique, expvar names can't be reused in process.
+var expvarRuns int32
+
+func TestPublishExpvar(t *testing.T) {
+	c := NewCache(10)
+	c.Set("aaa", 100)
+
+	name := fmt.Sprintf("%s_%d", t.Name(), atomic.AddInt32(&expvarRuns, 1))
+	PublishExpvar(name, c)
+
+	var stats Stats
+	require.NoError(t, json.Unmarshal([]byte(expvar.Get(name).String()), &stats))
+	require.Equal(t, Stats{Sets: 1, Size: 1, Capacity: 10}, stats)
+}
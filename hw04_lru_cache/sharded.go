@@ -0,0 +1,148 @@
+package hw04_lru_cache //nolint:golint,stylecheck
+
+import (
+	"runtime"
+	"time"
+)
+
+// shardedCache spreads keys across independent lruCache shards, so operations with keys
+// of different shards don't wait for each other. Every shard evicts its own least recently used value,
+// so eviction order is approximately LRU for the whole cache.
+type shardedCache struct {
+	shards  []*lruCache
+	janitor janitor
+}
+
+// minShardCapacity is the least capacity of shard, with smaller shards keys colliding
+// in one shard evict each other long before the whole cache is full.
+const minShardCapacity = 16
+
+// DefaultShards returns count of shards used when NewShardedCache gets non-positive count.
+func DefaultShards() int {
+	return 4 * runtime.GOMAXPROCS(0)
+}
+
+// ShardCount returns count of shards NewShardedCache creates for given capacity and shards.
+// Non-positive shards mean DefaultShards. Count is limited by capacity, so every shard holds
+// at least 16 values unless the whole cache is smaller, e.g. capacity 100 gives at most 6 shards.
+func ShardCount(capacity, shards int) int {
+	if shards <= 0 {
+		shards = DefaultShards()
+	}
+	if shards > capacity/minShardCapacity {
+		shards = capacity / minShardCapacity
+	}
+	if shards < 1 {
+		shards = 1
+	}
+
+	return shards
+}
+
+// NewShardedCache creates Cache of capacity split between ShardCount(capacity, shards) shards,
+// so requested count of shards is lowered for small capacity. Opts are applied to every shard.
+// When CleanupInterval is set Close must be called to stop background goroutine.
+func NewShardedCache(capacity, shards int, opts Options) Cache {
+	shards = ShardCount(capacity, shards)
+
+	c := &shardedCache{shards: make([]*lruCache, shards)}
+	for i := range c.shards {
+		// the first capacity % shards shards hold one extra value, so total capacity is exact
+		shardCapacity := capacity / shards
+		if i < capacity%shards {
+			shardCapacity++
+		}
+		c.shards[i] = newLRUCache(shardCapacity, opts)
+	}
+	c.janitor.start(opts.CleanupInterval, c.removeExpired)
+
+	return c
+}
+
+// shard returns shard of key.
+func (c *shardedCache) shard(key Key) *lruCache {
+	return c.shards[hashKey(key)%uint32(len(c.shards))]
+}
+
+// hashKey returns FNV-1a hash of key, it doesn't allocate unlike hash/fnv.
+func hashKey(key Key) uint32 {
+	const (
+		offset32 = 2166136261
+		prime32  = 16777619
+	)
+
+	h := uint32(offset32)
+	for i := 0; i < len(key); i++ {
+		h ^= uint32(key[i])
+		h *= prime32
+	}
+
+	return h
+}
+
+// Get returns {value, true} whether element with key stored in cache and {nil, false} pair otherwise.
+func (c *shardedCache) Get(key Key) (interface{}, bool) {
+	return c.shard(key).Get(key)
+}
+
+// Set stores value in cache with key and default TTL, returns whether element was already in cache.
+func (c *shardedCache) Set(key Key, value interface{}) bool {
+	return c.shard(key).Set(key, value)
+}
+
+// SetWithTTL stores value in cache with key, value expires after ttl, zero ttl means it never expires,
+// negative one means value is already expired.
+func (c *shardedCache) SetWithTTL(key Key, value interface{}, ttl time.Duration) bool {
+	return c.shard(key).SetWithTTL(key, value, ttl)
+}
+
+// Remove deletes element with key from cache, returns whether not expired element was in cache.
+func (c *shardedCache) Remove(key Key) bool {
+	return c.shard(key).Remove(key)
+}
+
+// Clear removes all elements from cache, shards are cleared one by one.
+func (c *shardedCache) Clear() {
+	for _, s := range c.shards {
+		s.Clear()
+	}
+}
+
+// Close stops background removal of expired elements, cache is still usable after Close.
+func (c *shardedCache) Close() {
+	c.janitor.stop()
+}
+
+// OnEvict adds fn to handlers of all shards.
+func (c *shardedCache) OnEvict(fn EvictFunc) {
+	for _, s := range c.shards {
+		s.OnEvict(fn)
+	}
+}
+
+// Stats returns sum of shard counters.
+func (c *shardedCache) Stats() Stats {
+	var total Stats
+	for _, s := range c.shards {
+		stats := s.Stats()
+		total.Hits += stats.Hits
+		total.Misses += stats.Misses
+		total.Sets += stats.Sets
+		total.Updates += stats.Updates
+		total.Evicted += stats.Evicted
+		total.Expired += stats.Expired
+		total.Removed += stats.Removed
+		total.Cleared += stats.Cleared
+		total.Size += stats.Size
+		total.Capacity += stats.Capacity
+	}
+
+	return total
+}
+
+// removeExpired removes expired elements of all shards.
+func (c *shardedCache) removeExpired() {
+	for _, s := range c.shards {
+		s.removeExpired()
+	}
+}
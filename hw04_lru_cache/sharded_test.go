@@ -0,0 +1,215 @@
+package hw04_lru_cache //nolint:golint,stylecheck
+
+import (
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestShardedCache(t *testing.T) {
+	t.Run("simple", func(t *testing.T) {
+		c := NewShardedCache(100, 8, Options{})
+
+		require.False(t, c.Set("aaa", 100))
+		require.False(t, c.Set("bbb", 200))
+		require.True(t, c.Set("aaa", 300))
+
+		val, ok := c.Get("aaa")
+		require.True(t, ok)
+		require.Equal(t, 300, val)
+
+		val, ok = c.Get("ccc")
+		require.False(t, ok)
+		require.Nil(t, val)
+
+		require.True(t, c.Remove("bbb"))
+		_, ok = c.Get("bbb")
+		require.False(t, ok)
+
+		c.Clear()
+		_, ok = c.Get("aaa")
+		require.False(t, ok)
+	})
+
+	t.Run("capacity", func(t *testing.T) {
+		for _, tt := range [...]struct {
+			name     string
+			capacity int
+			shards   int
+			expected []int
+		}{
+			{name: "even split", capacity: 64, shards: 4, expected: []int{16, 16, 16, 16}},
+			{name: "remainder", capacity: 70, shards: 4, expected: []int{18, 18, 17, 17}},
+			{name: "shards limited by min capacity", capacity: 40, shards: 4, expected: []int{20, 20}},
+			{name: "small cache", capacity: 10, shards: 4, expected: []int{10}},
+			{name: "zero capacity", capacity: 0, shards: 4, expected: []int{0}},
+		} {
+			t.Run(tt.name, func(t *testing.T) {
+				c := NewShardedCache(tt.capacity, tt.shards, Options{}).(*shardedCache)
+				capacities := make([]int, 0, len(c.shards))
+				for _, s := range c.shards {
+					capacities = append(capacities, s.capacity)
+				}
+				require.Equal(t, tt.expected, capacities)
+				require.Equal(t, len(tt.expected), ShardCount(tt.capacity, tt.shards))
+				require.Equal(t, tt.capacity, c.Stats().Capacity)
+			})
+		}
+
+		for _, capacity := range [...]int{0, -1} {
+			c := NewShardedCache(capacity, 4, Options{})
+			require.False(t, c.Set("aaa", 100))
+			_, ok := c.Get("aaa")
+			require.False(t, ok)
+			require.Zero(t, c.Stats().Size)
+		}
+
+		expected := DefaultShards()
+		if expected > 1000/minShardCapacity {
+			expected = 1000 / minShardCapacity
+		}
+		c := NewShardedCache(1000, 0, Options{}).(*shardedCache)
+		require.Len(t, c.shards, expected)
+		require.Equal(t, expected, ShardCount(1000, 0))
+		require.Equal(t, 6, ShardCount(100, 8))
+	})
+
+	t.Run("nothing is evicted below capacity", func(t *testing.T) {
+		for _, count := range [...]int{30, 50} {
+			c := NewShardedCache(100, 64, Options{})
+			for i := 0; i < count; i++ {
+				c.Set(Key(strconv.Itoa(i)), i)
+			}
+
+			stats := c.Stats()
+			require.Zero(t, stats.Evicted, "%d keys", count)
+			require.Equal(t, count, stats.Size)
+		}
+	})
+
+	t.Run("size never exceeds capacity", func(t *testing.T) {
+		c := NewShardedCache(10, 4, Options{})
+		for i := 0; i < 1000; i++ {
+			c.Set(Key(strconv.Itoa(i)), i)
+		}
+
+		stats := c.Stats()
+		require.Equal(t, 10, stats.Size)
+		require.Equal(t, uint64(1000), stats.Sets)
+		require.Equal(t, uint64(990), stats.Evicted)
+	})
+
+	t.Run("ttl and on evict", func(t *testing.T) {
+		clock := newFakeClock()
+		c := NewShardedCache(100, 4, Options{DefaultTTL: time.Minute, Now: clock.Now}).(*shardedCache)
+
+		var mu sync.Mutex
+		expired := map[Key]bool{}
+		c.OnEvict(func(key Key, _ interface{}, reason EvictReason) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			expired[key] = reason == EvictExpired
+		})
+
+		for i := 0; i < 10; i++ {
+			c.Set(Key(strconv.Itoa(i)), i)
+		}
+		clock.Add(time.Minute)
+		c.removeExpired()
+
+		require.Len(t, expired, 10)
+		require.Zero(t, c.Stats().Size)
+	})
+
+	t.Run("janitor", func(t *testing.T) {
+		clock := newFakeClock()
+		c := NewShardedCache(100, 4, Options{CleanupInterval: time.Millisecond, Now: clock.Now})
+		defer c.Close()
+
+		for i := 0; i < 10; i++ {
+			c.SetWithTTL(Key(strconv.Itoa(i)), i, time.Minute)
+		}
+		clock.Add(time.Minute)
+
+		require.Eventually(t, func() bool {
+			return c.Stats().Size == 0
+		}, time.Second, time.Millisecond)
+	})
+}
+
+func TestShardedCacheMultithreading(t *testing.T) {
+	c := NewShardedCache(100, 8, Options{})
+	wg := &sync.WaitGroup{}
+
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 10_000; i++ {
+				key := Key(strconv.Itoa(i % 300))
+				if i%3 == g%3 {
+					c.Set(key, i)
+				} else if val, ok := c.Get(key); ok {
+					require.IsType(t, 0, val)
+				}
+			}
+		}(g)
+	}
+
+	wg.Wait()
+	require.LessOrEqual(t, c.Stats().Size, 100)
+}
+
+// BenchmarkCacheParallel compares caches under parallel load with 90% of reads,
+// run it with -cpu 1,4,8 on a multi-core host to see scaling.
+// Medians of 3 runs below are from 1 CPU host, where goroutines never contend for lock,
+// so sharding only adds hashing. Results of multi-core host aren't recorded yet.
+//
+// BenchmarkCacheParallel/single_mutex       111.7 ns/op       0 B/op       0 allocs/op
+// BenchmarkCacheParallel/single_mutex-4     121.9 ns/op       0 B/op       0 allocs/op
+// BenchmarkCacheParallel/single_mutex-8     122.5 ns/op       0 B/op       0 allocs/op
+// BenchmarkCacheParallel/sharded            127.8 ns/op       0 B/op       0 allocs/op
+// BenchmarkCacheParallel/sharded-4          140.4 ns/op       0 B/op       0 allocs/op
+// BenchmarkCacheParallel/sharded-8          140.5 ns/op       0 B/op       0 allocs/op
+func BenchmarkCacheParallel(b *testing.B) {
+	keys := make([]Key, 10_000)
+	for i := range keys {
+		keys[i] = Key(strconv.Itoa(i))
+	}
+
+	for _, bc := range [...]struct {
+		name  string
+		cache func() Cache
+	}{
+		{"single mutex", func() Cache { return NewCache(len(keys) / 2) }},
+		{"sharded", func() Cache { return NewShardedCache(len(keys)/2, 0, Options{}) }},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			c := bc.cache()
+			for i, key := range keys {
+				c.Set(key, i)
+			}
+
+			var goroutines int64
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				// every goroutine walks keys from its own offset, so they don't hit the same shard together
+				i := int(atomic.AddInt64(&goroutines, 1)) * 7_777
+				for pb.Next() {
+					key := keys[(i*7919)%len(keys)]
+					if i%10 == 0 {
+						c.Set(key, i)
+					} else {
+						c.Get(key)
+					}
+					i++
+				}
+			})
+		})
+	}
+}